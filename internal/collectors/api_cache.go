@@ -0,0 +1,127 @@
+package collectors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// apiCacheEntry is a GitHub API response body kept alongside the validators
+// needed to make the next request for the same URL conditional
+type apiCacheEntry struct {
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// setConditionalHeaders asks GitHub to only send the body if it has changed
+func (e *apiCacheEntry) setConditionalHeaders(req *http.Request) {
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+
+	if e.lastModified != "" {
+		req.Header.Set("If-Modified-Since", e.lastModified)
+	}
+}
+
+// response rebuilds a 200 response from the cached headers and body
+func (e *apiCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// apiCache holds the most recent response for each GitHub API URL that was
+// served with an ETag or Last-Modified header
+type apiCache struct {
+	mu      sync.Mutex
+	entries map[string]*apiCacheEntry
+}
+
+func newAPICache() *apiCache {
+	return &apiCache{
+		entries: make(map[string]*apiCacheEntry),
+	}
+}
+
+func (c *apiCache) get(key string) (*apiCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+
+	return entry, ok
+}
+
+// store caches a successful response when it carries validators. The body is
+// read in full, so the returned response must be used in place of resp.
+func (c *apiCache) store(key string, resp *http.Response) (*http.Response, error) {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		slog.Error("Error closing response body", "error", closeErr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	entry := &apiCacheEntry{
+		etag:         etag,
+		lastModified: lastModified,
+		header:       resp.Header.Clone(),
+		body:         body,
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// forgetPackage drops the cached info and version pages of a package that is
+// no longer collected, so discovered packages that are deleted don't keep
+// their responses in memory
+func (c *apiCache) forgetPackage(owner, repo string) {
+	suffix := strings.ToLower("/" + owner + "/packages/container/" + repo)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		requestURL, err := url.Parse(key)
+		if err != nil {
+			continue
+		}
+
+		path := strings.TrimSuffix(strings.ToLower(requestURL.Path), "/versions")
+		if strings.HasSuffix(path, suffix) {
+			delete(c.entries, key)
+		}
+	}
+}
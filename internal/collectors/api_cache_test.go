@@ -0,0 +1,141 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAPICacheServesNotModifiedResponses(t *testing.T) {
+	var fullResponses atomic.Int32
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		if page == "" {
+			page = "1"
+		}
+
+		etag := `"versions-page-` + page + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fullResponses.Add(1)
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+
+		if page == "1" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=2>; rel="next"`, server.URL, r.URL.Path))
+			_, _ = w.Write([]byte(`[{"id": 1, "created_at": "2025-10-01T12:00:00Z"}]`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`[{"id": 2, "created_at": "2025-10-02T12:00:00Z"}]`))
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	for cycle := 1; cycle <= 2; cycle++ {
+		versions, _, err := collector.getPackageVersions(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+		if err != nil {
+			t.Fatalf("Cycle %d: expected no error, got: %v", cycle, err)
+		}
+
+		if len(versions) != 2 || versions[0].ID != 1 || versions[1].ID != 2 {
+			t.Fatalf("Cycle %d: expected versions 1 and 2, got %+v", cycle, versions)
+		}
+	}
+
+	if got := fullResponses.Load(); got != 2 {
+		t.Errorf("Expected only the first cycle to fetch full bodies (2 pages), got %d", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.APICacheMissesCounter); got != 2 {
+		t.Errorf("Expected 2 cache misses, got %f", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.APICacheHitsCounter); got != 2 {
+		t.Errorf("Expected 2 cache hits, got %f", got)
+	}
+}
+
+func TestAPICacheSkipsResponsesWithoutValidators(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Error("Expected unconditional request when nothing is cached")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "filesystem-exporter", "version_count": 3}`))
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	for range 2 {
+		info, err := collector.getPackageInfo(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if info.VersionCount != 3 {
+			t.Errorf("Expected version count 3, got %d", info.VersionCount)
+		}
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.APICacheHitsCounter); got != 0 {
+		t.Errorf("Expected no cache hits, got %f", got)
+	}
+}
+
+func TestAPICacheForgetsDeletedPackages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/versions") {
+			_, _ = w.Write([]byte(`[{"id": 1, "created_at": "2025-10-01T12:00:00Z"}]`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name": "exporter", "version_count": 1}`))
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	for _, packageName := range []string{"filesystem-exporter", "mqtt-exporter"} {
+		if _, err := collector.getPackageInfo(context.Background(), "d0ugal", packageName, packageName); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if _, _, err := collector.getPackageVersions(context.Background(), "d0ugal", packageName, packageName); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+
+	collector.deletePackageMetrics("d0ugal", "filesystem-exporter")
+
+	for key := range collector.apiCache.entries {
+		if strings.Contains(key, "filesystem-exporter") {
+			t.Errorf("Expected the deleted package's responses to be dropped, found %s", key)
+		}
+	}
+
+	if got := len(collector.apiCache.entries); got != 2 {
+		t.Errorf("Expected the other package's 2 responses to be kept, got %d", got)
+	}
+}
@@ -0,0 +1,129 @@
+package collectors
+
+import (
+	"log/slog"
+	"regexp"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// channelMatcher is a compiled channel rule
+type channelMatcher struct {
+	re       *regexp.Regexp
+	template string
+}
+
+// newChannelMatchers compiles the channel rules of a group. Rules are
+// validated when the config is loaded, so invalid ones are only logged.
+func newChannelMatchers(pkg config.PackageGroup) []channelMatcher {
+	matchers := make([]channelMatcher, 0, len(pkg.Channels))
+
+	for _, rule := range pkg.Channels {
+		re, err := rule.Compile()
+		if err != nil {
+			slog.Error("Skipping invalid channel rule", "owner", pkg.Owner, "pattern", rule.Pattern, "error", err)
+			continue
+		}
+
+		template := rule.Channel
+		if template == "" {
+			template = "${channel}"
+		}
+
+		matchers = append(matchers, channelMatcher{re: re, template: template})
+	}
+
+	return matchers
+}
+
+// channelFor returns the channel of the first rule matching tag
+func channelFor(matchers []channelMatcher, tag string) (string, bool) {
+	for _, matcher := range matchers {
+		match := matcher.re.FindStringSubmatchIndex(tag)
+		if match == nil {
+			continue
+		}
+
+		channel := string(matcher.re.ExpandString(nil, matcher.template, tag, match))
+
+		return channel, channel != ""
+	}
+
+	return "", false
+}
+
+// channelStats is what the channel metrics are built from
+type channelStats struct {
+	versions      int
+	lastPublished time.Time
+}
+
+// summarizeChannels counts the versions in each channel and finds when each
+// channel was last published to. A version with several tags in the same
+// channel is only counted once.
+func summarizeChannels(matchers []channelMatcher, versions []GHCRVersionResponse) map[string]*channelStats {
+	channels := make(map[string]*channelStats)
+
+	for _, version := range versions {
+		created := parseVersionTime(version.CreatedAt)
+		versionChannels := make(map[string]bool)
+
+		for _, tag := range version.Metadata.Container.Tags {
+			if channel, ok := channelFor(matchers, tag); ok {
+				versionChannels[channel] = true
+			}
+		}
+
+		for channel := range versionChannels {
+			stats, ok := channels[channel]
+			if !ok {
+				stats = &channelStats{}
+				channels[channel] = stats
+			}
+
+			stats.versions++
+
+			if created.After(stats.lastPublished) {
+				stats.lastPublished = created
+			}
+		}
+	}
+
+	return channels
+}
+
+// updateChannelMetrics exports per-channel version counts and last published
+// timestamps for groups with channel rules
+func (gc *GHCRCollector) updateChannelMetrics(pkg config.PackageGroup, versions []GHCRVersionResponse) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	gc.metrics.PackageChannelVersionsGauge.DeletePartialMatch(labels)
+	gc.metrics.PackageChannelLastPublishedGauge.DeletePartialMatch(labels)
+
+	if len(pkg.Channels) == 0 {
+		return
+	}
+
+	channels := summarizeChannels(newChannelMatchers(pkg), versions)
+
+	for channel, stats := range channels {
+		channelLabels := prometheus.Labels{
+			"owner":   pkg.Owner,
+			"repo":    pkg.Repo,
+			"channel": channel,
+		}
+
+		gc.metrics.PackageChannelVersionsGauge.With(channelLabels).Set(float64(stats.versions))
+
+		if !stats.lastPublished.IsZero() {
+			gc.metrics.PackageChannelLastPublishedGauge.With(channelLabels).Set(float64(stats.lastPublished.Unix()))
+		}
+	}
+
+	slog.Debug("Updated channel metrics", "owner", pkg.Owner, "package", pkg.Repo, "channels", len(channels))
+}
@@ -0,0 +1,91 @@
+package collectors
+
+import (
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestChannelFor(t *testing.T) {
+	matchers := newChannelMatchers(config.PackageGroup{
+		Owner: "d0ugal",
+		Channels: []config.ChannelRule{
+			{Pattern: `^(?P<channel>nightly)-[0-9]{4}-[0-9]{2}-[0-9]{2}$`},
+			{Pattern: `^stable-(?P<major>[0-9]+)\.[0-9]+$`, Channel: "stable-v${major}"},
+			{Pattern: `^pr-[0-9]+$`, Channel: "pr"},
+			{Pattern: `^(?P<channel>[a-z]+)?-dev$`},
+		},
+	})
+
+	testCases := []struct {
+		tag      string
+		expected string
+	}{
+		{tag: "nightly-2026-10-01", expected: "nightly"},
+		{tag: "stable-3.4", expected: "stable-v3"},
+		{tag: "pr-1234", expected: "pr"},
+		{tag: "v1.2.3", expected: ""},
+		{tag: "-dev", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			channel, ok := channelFor(matchers, tc.tag)
+			if channel != tc.expected || ok != (tc.expected != "") {
+				t.Errorf("Expected channel %q, got %q (matched: %t)", tc.expected, channel, ok)
+			}
+		})
+	}
+}
+
+func TestUpdateChannelMetrics(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{
+		Owner: "d0ugal",
+		Repo:  "mqtt-exporter",
+		Channels: []config.ChannelRule{
+			{Pattern: `^(?P<channel>nightly|stable)-`},
+		},
+	}
+
+	publish := func(id int, createdAt string, tags ...string) GHCRVersionResponse {
+		version := testVersion(id, createdAt, tags...)
+		version.CreatedAt = createdAt
+
+		return version
+	}
+
+	collector.updateChannelMetrics(pkg, []GHCRVersionResponse{
+		publish(1, "2026-09-30T02:00:00Z", "nightly-2026-09-30"),
+		publish(2, "2026-10-01T02:00:00Z", "nightly-2026-10-01", "nightly-latest"),
+		publish(3, "2026-09-01T12:00:00Z", "stable-3.4", "v3.4.0"),
+		publish(4, "2026-10-01T12:00:00Z", "pr-1234"),
+	})
+
+	testCases := []struct {
+		channel       string
+		versions      float64
+		lastPublished float64
+	}{
+		{channel: "nightly", versions: 2, lastPublished: 1790820000},
+		{channel: "stable", versions: 1, lastPublished: 1788264000},
+	}
+
+	for _, tc := range testCases {
+		labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter", "channel": tc.channel}
+
+		if got := testutil.ToFloat64(collector.metrics.PackageChannelVersionsGauge.With(labels)); got != tc.versions {
+			t.Errorf("Expected %v versions in %s, got %v", tc.versions, tc.channel, got)
+		}
+
+		if got := testutil.ToFloat64(collector.metrics.PackageChannelLastPublishedGauge.With(labels)); got != tc.lastPublished {
+			t.Errorf("Expected %s last published at %v, got %v", tc.channel, tc.lastPublished, got)
+		}
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageChannelVersionsGauge); got != 2 {
+		t.Errorf("Expected 2 channels, got %d", got)
+	}
+}
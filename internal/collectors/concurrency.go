@@ -0,0 +1,62 @@
+package collectors
+
+import (
+	"context"
+	"sync"
+)
+
+// collectionLimiter bounds how many packages are collected at once, both in
+// total and for each owner
+type collectionLimiter struct {
+	global chan struct{}
+
+	mu         sync.Mutex
+	owners     map[string]chan struct{}
+	ownerLimit int
+}
+
+func newCollectionLimiter(globalLimit, ownerLimit int) *collectionLimiter {
+	return &collectionLimiter{
+		global:     make(chan struct{}, globalLimit),
+		owners:     make(map[string]chan struct{}),
+		ownerLimit: ownerLimit,
+	}
+}
+
+func (l *collectionLimiter) ownerSlots(owner string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.owners[owner]
+	if !ok {
+		slots = make(chan struct{}, l.ownerLimit)
+		l.owners[owner] = slots
+	}
+
+	return slots
+}
+
+// acquire blocks until a slot is free for the owner, returning a function
+// that releases it. The owner slot is taken first so that an owner at its own
+// limit doesn't hold global slots other owners could use.
+func (l *collectionLimiter) acquire(ctx context.Context, owner string) (func(), error) {
+	ownerSlots := l.ownerSlots(owner)
+
+	select {
+	case ownerSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case l.global <- struct{}{}:
+	case <-ctx.Done():
+		<-ownerSlots
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-l.global
+		<-ownerSlots
+	}, nil
+}
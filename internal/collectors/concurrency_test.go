@@ -0,0 +1,213 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	promexporter_config "github.com/d0ugal/promexporter/config"
+)
+
+func TestCollectionLimiterOwnerLimit(t *testing.T) {
+	limiter := newCollectionLimiter(3, 1)
+
+	release, err := limiter.acquire(context.Background(), "d0ugal")
+	if err != nil {
+		t.Fatalf("Expected first acquire to succeed, got: %v", err)
+	}
+
+	// A second slot for the same owner must wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.acquire(ctx, "d0ugal"); err == nil {
+		t.Fatal("Expected acquire to fail while the owner is at its limit")
+	}
+
+	// Other owners are unaffected
+	otherRelease, err := limiter.acquire(context.Background(), "home-assistant")
+	if err != nil {
+		t.Fatalf("Expected acquire for another owner to succeed, got: %v", err)
+	}
+
+	otherRelease()
+	release()
+
+	if release, err = limiter.acquire(context.Background(), "d0ugal"); err != nil {
+		t.Fatalf("Expected acquire after release to succeed, got: %v", err)
+	}
+
+	release()
+}
+
+func TestCollectionLimiterGlobalLimit(t *testing.T) {
+	limiter := newCollectionLimiter(1, 5)
+
+	release, err := limiter.acquire(context.Background(), "d0ugal")
+	if err != nil {
+		t.Fatalf("Expected first acquire to succeed, got: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := limiter.acquire(ctx, "home-assistant"); err == nil {
+		t.Fatal("Expected acquire to fail while the global limit is reached")
+	}
+
+	// The failed acquire must have given back its owner slot
+	if got := len(limiter.ownerSlots("home-assistant")); got != 0 {
+		t.Errorf("Expected owner slot to be released, %d still held", got)
+	}
+}
+
+func TestCollectOwnerPackagesInParallel(t *testing.T) {
+	const packageCount = 6
+
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/users/d0ugal/packages":
+			names := make([]string, packageCount)
+			for i := range names {
+				names[i] = fmt.Sprintf(`{"name": "exporter-%d"}`, i)
+			}
+
+			_, _ = w.Write([]byte("[" + strings.Join(names, ",") + "]"))
+		case strings.HasSuffix(r.URL.Path, "/versions"):
+			_, _ = w.Write([]byte(`[]`))
+		case strings.HasPrefix(r.URL.Path, "/users/d0ugal/packages/container/"):
+			current := inFlight.Add(1)
+			for {
+				previous := maxInFlight.Load()
+				if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+					break
+				}
+			}
+
+			time.Sleep(30 * time.Millisecond)
+			inFlight.Add(-1)
+
+			_, _ = w.Write([]byte(`{"version_count": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	group := config.PackageGroup{Owner: "d0ugal"}
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{Token: promexporter_config.NewSensitiveString("test-token")},
+		Collector: config.CollectorConfig{Concurrency: 10, OwnerConcurrency: 3},
+		Packages:  []config.PackageGroup{group},
+	}
+	collector := newTestCollector(t, cfg, server)
+
+	collector.collectOwnerPackages(context.Background(), group.GetName(), group)
+
+	if got := maxInFlight.Load(); got < 2 || got > 3 {
+		t.Errorf("Expected between 2 and 3 packages collected concurrently, got %d", got)
+	}
+}
+
+func TestCollectOwnerPackagesStopsAtDeadline(t *testing.T) {
+	var collected atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/users/d0ugal/packages" {
+			_, _ = w.Write([]byte(`[{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}]`))
+			return
+		}
+
+		collected.Add(1)
+
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	group := config.PackageGroup{Owner: "d0ugal"}
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{Token: promexporter_config.NewSensitiveString("test-token")},
+		Collector: config.CollectorConfig{Concurrency: 1, OwnerConcurrency: 1},
+		Packages:  []config.PackageGroup{group},
+	}
+	collector := newTestCollector(t, cfg, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	collector.collectOwnerPackages(ctx, group.GetName(), group)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected collection to stop at the deadline, took %s", elapsed)
+	}
+
+	if got := collected.Load(); got >= 4 {
+		t.Errorf("Expected remaining packages to be skipped after the deadline, %d were requested", got)
+	}
+}
+
+func TestCollectOwnerPackagesReleasesSlotsDuringBackoff(t *testing.T) {
+	var healthyAt atomic.Int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/users/d0ugal/packages":
+			_, _ = w.Write([]byte(`[{"name": "broken"}, {"name": "healthy"}]`))
+		case strings.HasPrefix(r.URL.Path, "/users/d0ugal/packages/container/broken"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/versions"):
+			_, _ = w.Write([]byte(`[]`))
+		case r.URL.Path == "/users/d0ugal/packages/container/healthy":
+			healthyAt.CompareAndSwap(0, time.Now().UnixNano())
+			_, _ = w.Write([]byte(`{"version_count": 1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	group := config.PackageGroup{Owner: "d0ugal"}
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{Token: promexporter_config.NewSensitiveString("test-token")},
+		Collector: config.CollectorConfig{Concurrency: 1, OwnerConcurrency: 1},
+		Packages:  []config.PackageGroup{group},
+	}
+	collector := newTestCollector(t, cfg, server)
+	collector.retry = retryPolicy{attempts: 3, baseDelay: 400 * time.Millisecond, maxDelay: 400 * time.Millisecond}
+
+	start := time.Now()
+
+	collector.collectOwnerPackages(context.Background(), group.GetName(), group)
+
+	// The broken package spends at least 400ms backing off; the healthy one
+	// must not wait for it
+	if healthyAt.Load() == 0 {
+		t.Fatal("Expected the healthy package to be collected")
+	}
+
+	if waited := time.Duration(healthyAt.Load() - start.UnixNano()); waited > 150*time.Millisecond {
+		t.Errorf("Expected the healthy package to be collected while the broken one backed off, waited %s", waited)
+	}
+}
@@ -5,12 +5,16 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"ghcr-exporter/internal/config"
@@ -22,12 +26,38 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+const (
+	defaultAPIBaseURL = "https://api.github.com"
+	defaultWebBaseURL = "https://github.com"
+
+	// apiPageSize is the largest page size the GitHub packages API allows
+	apiPageSize = 100
+)
+
 type GHCRCollector struct {
 	config  *config.Config
 	metrics *metrics.GHCRRegistry
 	app     *app.App
 	client  *http.Client
 	token   string
+
+	// Base URLs for the GitHub REST API and web UI, overridable in tests
+	apiBaseURL string
+	webBaseURL string
+
+	// registry reads image manifests from the OCI registry serving the packages
+	registry *registryClient
+	images   *imageCache
+	storage  *storageCache
+
+	rateLimit  *rateLimiter
+	apiCache   *apiCache
+	ownerTypes *ownerTypeCache
+	packages   *packageTracker
+	versions   *versionTracker
+	tags       *tagTracker
+	limiter    *collectionLimiter
+	retry      retryPolicy
 }
 
 // GHCRPackageResponse represents the response from GHCR API
@@ -48,6 +78,7 @@ type GHCRPackageResponse struct {
 	VersionCount int    `json:"version_count"`
 	Visibility   string `json:"visibility"`
 	URL          string `json:"url"`
+	HTMLURL      string `json:"html_url"`
 }
 
 // GHCRVersionResponse represents the response for package versions
@@ -76,15 +107,30 @@ type GHCRVersionResponse struct {
 }
 
 func NewGHCRCollector(cfg *config.Config, registry *metrics.GHCRRegistry, app *app.App) *GHCRCollector {
+	client := &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+	}
+
 	return &GHCRCollector{
-		config:  cfg,
-		metrics: registry,
-		app:     app,
-		client: &http.Client{
-			Timeout:   30 * time.Second,
-			Transport: otelhttp.NewTransport(http.DefaultTransport),
-		},
-		token: cfg.GitHub.Token.Value(),
+		config:     cfg,
+		metrics:    registry,
+		app:        app,
+		client:     client,
+		token:      cfg.GitHub.Token.Value(),
+		apiBaseURL: defaultAPIBaseURL,
+		webBaseURL: defaultWebBaseURL,
+		registry:   newRegistryClient(client, cfg.GetRegistryURL(), cfg.GitHub.Token.Value()),
+		images:     newImageCache(),
+		storage:    newStorageCache(),
+		rateLimit:  &rateLimiter{},
+		apiCache:   newAPICache(),
+		ownerTypes: newOwnerTypeCache(cfg.Packages),
+		packages:   newPackageTracker(),
+		versions:   newVersionTracker(),
+		tags:       newTagTracker(),
+		limiter:    newCollectionLimiter(cfg.GetConcurrency(), cfg.GetOwnerConcurrency()),
+		retry:      newRetryPolicy(cfg),
 	}
 }
 
@@ -132,6 +178,11 @@ func (gc *GHCRCollector) run(ctx context.Context) {
 func (gc *GHCRCollector) collectSinglePackage(ctx context.Context, name string, pkg config.PackageGroup) {
 	startTime := time.Now()
 	interval := gc.config.GetPackageInterval(pkg)
+	timeout := gc.config.GetPackageTimeout(pkg)
+
+	// Bound the whole cycle so a slow package can't overrun its own interval
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
 	// Create span for collection cycle
 	tracer := gc.app.GetTracer()
@@ -149,6 +200,7 @@ func (gc *GHCRCollector) collectSinglePackage(ctx context.Context, name string,
 			attribute.String("package.owner", pkg.Owner),
 			attribute.String("package.repo", pkg.Repo),
 			attribute.Int("package.interval", interval),
+			attribute.Float64("package.timeout_seconds", timeout.Seconds()),
 		)
 
 		spanCtx = collectorSpan.Context() //nolint:contextcheck // Standard OpenTelemetry pattern: extract context from span
@@ -157,6 +209,20 @@ func (gc *GHCRCollector) collectSinglePackage(ctx context.Context, name string,
 		spanCtx = ctx
 	}
 
+	// Don't spend a cycle on requests GitHub is going to reject
+	if err := gc.rateLimit.check(); err != nil {
+		slog.Warn("Skipping collection while GitHub API rate limit is exhausted", "name", name, "error", err)
+
+		if collectorSpan != nil {
+			collectorSpan.AddEvent("collection_skipped_rate_limited",
+				attribute.String("package.name", name),
+				attribute.String("error", err.Error()),
+			)
+		}
+
+		return
+	}
+
 	// If repo is not specified, discover all repos for the owner
 	if pkg.Repo == "" {
 		gc.collectOwnerPackages(spanCtx, name, pkg)
@@ -173,20 +239,32 @@ func (gc *GHCRCollector) collectSinglePackage(ctx context.Context, name string,
 		)
 	}
 
-	// Retry with exponential backoff
+	// Retry transient failures with backoff
 	retryStart := time.Now()
-	err := gc.retryWithBackoff(func() error {
+	err := gc.retry.do(spanCtx, func() error {
+		release, err := gc.limiter.acquire(spanCtx, pkg.Owner)
+		if err != nil {
+			return err
+		}
+		defer release()
+
 		return gc.collectPackageMetrics(spanCtx, pkg.Repo, pkg)
-	}, 3, 2*time.Second)
+	})
 	retryDuration := time.Since(retryStart).Seconds()
 
 	if err != nil {
 		slog.Error("Failed to collect package metrics after retries", "name", name, "error", err)
 
+		// A package that no longer exists keeps its series until the grace period ends
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			gc.removeStalePackages(name, pkg.Owner, time.Now())
+		}
+
 		if collectorSpan != nil {
 			collectorSpan.SetAttributes(
 				attribute.Float64("retry.duration_seconds", retryDuration),
-				attribute.Int("retry.attempts", 3),
+				attribute.Int("retry.attempts", gc.retry.attempts),
 			)
 			collectorSpan.RecordError(err, attribute.String("package.name", name))
 			collectorSpan.AddEvent("collection_failed",
@@ -212,6 +290,8 @@ func (gc *GHCRCollector) collectSinglePackage(ctx context.Context, name string,
 		)
 	}
 
+	gc.packages.markSeen(name, pkg.Repo, time.Now())
+
 	gc.metrics.CollectionSuccessCounter.With(prometheus.Labels{
 		"repo":     name,
 		"interval": strconv.Itoa(interval),
@@ -274,7 +354,15 @@ func (gc *GHCRCollector) collectOwnerPackages(ctx context.Context, name string,
 
 	// Get all packages for the owner
 	discoveryStart := time.Now()
-	packages, err := gc.getOwnerPackages(spanCtx, pkg.Owner)
+	var packages []GHCRPackageResponse
+
+	err := gc.retry.do(spanCtx, func() error {
+		var err error
+
+		packages, err = gc.getOwnerPackages(spanCtx, pkg.Owner)
+
+		return err
+	})
 	discoveryDuration := time.Since(discoveryStart).Seconds()
 
 	if err != nil {
@@ -309,35 +397,131 @@ func (gc *GHCRCollector) collectOwnerPackages(ctx context.Context, name string,
 
 	slog.Info("Discovered packages for owner", "name", name, "owner", pkg.Owner, "package_count", len(packages))
 
-	// Collect metrics for each discovered package
-	successCount := 0
+	discoveredCount := len(packages)
+	gc.metrics.OwnerPackagesDiscoveredGauge.With(prometheus.Labels{
+		"owner": pkg.Owner,
+	}).Set(float64(discoveredCount))
+
+	packages, filtered := filterOwnerPackages(gc.config, pkg, packages)
 
-	for _, discoveredPkg := range packages {
-		// Create a PackageGroup for the discovered package
-		discoveredGroup := config.PackageGroup{
-			Owner: pkg.Owner,
-			Repo:  discoveredPkg.Name,
+	for _, reason := range []string{config.FilterReasonVisibility, config.FilterReasonInclude, config.FilterReasonExclude, config.FilterReasonConfigured} {
+		gc.metrics.OwnerPackagesFilteredGauge.With(prometheus.Labels{
+			"owner":  pkg.Owner,
+			"reason": reason,
+		}).Set(float64(filtered[reason]))
+	}
+
+	if pkg.HasFilters() {
+		slog.Info("Filtered discovered packages",
+			"name", name,
+			"owner", pkg.Owner,
+			"discovered", discoveredCount,
+			"selected", len(packages),
+			"filtered_visibility", filtered[config.FilterReasonVisibility],
+			"filtered_include", filtered[config.FilterReasonInclude],
+			"filtered_exclude", filtered[config.FilterReasonExclude],
+			"filtered_configured", filtered[config.FilterReasonConfigured])
+
+		if collectorSpan != nil {
+			collectorSpan.AddEvent("discovery_filtered",
+				attribute.Int("discovered", discoveredCount),
+				attribute.Int("selected", len(packages)),
+			)
 		}
+	}
 
-		err := gc.collectPackageMetrics(spanCtx, discoveredPkg.Name, discoveredGroup)
-		if err != nil {
-			slog.Warn("Failed to collect metrics for discovered package",
-				"name", name,
-				"owner", pkg.Owner,
-				"package", discoveredPkg.Name,
-				"error", err)
-		} else {
-			successCount++
+	// Packages that were deleted, renamed or are now filtered out stop being
+	// seen and have their series removed once the grace period has passed
+	seenAt := time.Now()
+	for _, selectedPkg := range packages {
+		gc.packages.markSeen(name, selectedPkg.Name, seenAt)
+	}
+
+	gc.removeStalePackages(name, pkg.Owner, seenAt)
+
+	// Collect metrics for each discovered package, bounded by the global and
+	// per-owner concurrency limits. Slots are taken for each attempt, so a
+	// package waiting to retry doesn't hold one through its backoff.
+	var (
+		wg           sync.WaitGroup
+		successCount atomic.Int64
+		startedCount atomic.Int64
+	)
+
+	for _, discoveredPkg := range packages {
+		wg.Add(1)
+
+		go func(discoveredPkg GHCRPackageResponse) {
+			defer wg.Done()
+
+			// Create a PackageGroup for the discovered package, inheriting
+			// the owner group's settings
+			discoveredGroup := pkg
+			discoveredGroup.Repo = discoveredPkg.Name
+
+			started := false
+
+			err := gc.retry.do(spanCtx, func() error {
+				release, err := gc.limiter.acquire(spanCtx, pkg.Owner)
+				if err != nil {
+					return err
+				}
+				defer release()
+
+				if !started {
+					started = true
+
+					startedCount.Add(1)
+				}
+
+				return gc.collectPackageMetrics(spanCtx, discoveredPkg.Name, discoveredGroup)
+			})
+			if err != nil {
+				slog.Warn("Failed to collect metrics for discovered package",
+					"name", name,
+					"owner", pkg.Owner,
+					"package", discoveredPkg.Name,
+					"error", err)
+			} else {
+				successCount.Add(1)
+			}
+		}(discoveredPkg)
+	}
+
+	wg.Wait()
+
+	if err := spanCtx.Err(); err != nil {
+		slog.Error("Owner package collection did not finish before the cycle deadline",
+			"name", name,
+			"owner", pkg.Owner,
+			"total_packages", len(packages),
+			"started", startedCount.Load(),
+			"successful_collections", successCount.Load(),
+			"error", err)
+
+		if collectorSpan != nil {
+			collectorSpan.RecordError(err, attribute.String("package.owner", pkg.Owner))
+			collectorSpan.AddEvent("collection_incomplete",
+				attribute.Int64("started", startedCount.Load()),
+				attribute.Int("total", len(packages)),
+			)
 		}
+
+		gc.metrics.CollectionFailedCounter.With(prometheus.Labels{
+			"repo":     name,
+			"interval": strconv.Itoa(interval),
+		}).Inc()
+
+		return
 	}
 
 	if collectorSpan != nil {
 		collectorSpan.SetAttributes(
-			attribute.Int("collection.successful", successCount),
+			attribute.Int64("collection.successful", successCount.Load()),
 			attribute.Int("collection.total", len(packages)),
 		)
 		collectorSpan.AddEvent("collection_completed",
-			attribute.Int("successful", successCount),
+			attribute.Int64("successful", successCount.Load()),
 			attribute.Int("total", len(packages)),
 		)
 	}
@@ -365,10 +549,37 @@ func (gc *GHCRCollector) collectOwnerPackages(ctx context.Context, name string,
 		"name", name,
 		"owner", pkg.Owner,
 		"total_packages", len(packages),
-		"successful_collections", successCount,
+		"successful_collections", successCount.Load(),
 		"duration", duration)
 }
 
+// filterOwnerPackages applies the group's discovery filters, returning the
+// packages to collect and how many were skipped for each reason. Repos with
+// their own package group are skipped, so only one group's settings apply.
+func filterOwnerPackages(cfg *config.Config, pkg config.PackageGroup, packages []GHCRPackageResponse) ([]GHCRPackageResponse, map[string]int) {
+	filtered := make(map[string]int)
+	selected := make([]GHCRPackageResponse, 0, len(packages))
+
+	for _, discoveredPkg := range packages {
+		reason := pkg.FilterReason(discoveredPkg.Name, discoveredPkg.Visibility)
+		if reason == "" && cfg.HasRepoGroup(pkg.Owner, discoveredPkg.Name) {
+			reason = config.FilterReasonConfigured
+		}
+
+		if reason != "" {
+			slog.Debug("Skipping filtered package", "owner", pkg.Owner, "package", discoveredPkg.Name, "reason", reason)
+
+			filtered[reason]++
+
+			continue
+		}
+
+		selected = append(selected, discoveredPkg)
+	}
+
+	return selected, filtered
+}
+
 func (gc *GHCRCollector) collectPackageMetrics(ctx context.Context, repo string, pkg config.PackageGroup) error {
 	tracer := gc.app.GetTracer()
 
@@ -433,8 +644,9 @@ func (gc *GHCRCollector) collectPackageMetrics(ctx context.Context, repo string,
 
 	// Get package versions for more detailed metrics
 	versionsStart := time.Now()
-	versions, err := gc.getPackageVersions(spanCtx, pkg.Owner, pkg.Repo, pkg.Repo)
+	versions, truncated, err := gc.getPackageVersions(spanCtx, pkg.Owner, pkg.Repo, pkg.Repo)
 	versionsDuration := time.Since(versionsStart).Seconds()
+	haveVersions := err == nil
 
 	if err != nil {
 		slog.Warn("Failed to get package versions", "error", err)
@@ -465,7 +677,7 @@ func (gc *GHCRCollector) collectPackageMetrics(ctx context.Context, repo string,
 	// Update metrics
 	updateStart := time.Now()
 
-	gc.updatePackageMetrics(spanCtx, pkg, packageInfo, versions)
+	gc.updatePackageMetrics(spanCtx, pkg, packageInfo, versions, haveVersions, truncated)
 
 	updateDuration := time.Since(updateStart).Seconds()
 
@@ -502,8 +714,17 @@ func (gc *GHCRCollector) getPackageInfo(ctx context.Context, owner, repo, packag
 		spanCtx = ctx
 	}
 
+	ownerPath, err := gc.ownerPath(spanCtx, owner)
+	if err != nil {
+		if collectorSpan != nil {
+			collectorSpan.RecordError(err, attribute.String("operation", "resolve-owner-type"))
+		}
+
+		return nil, err
+	}
+
 	apiStart := time.Now()
-	resp, err := gc.makeGitHubAPIRequest(spanCtx, fmt.Sprintf("/users/%s/packages/container/%s", owner, packageName))
+	resp, err := gc.makeGitHubAPIRequest(spanCtx, fmt.Sprintf("%s/packages/container/%s", ownerPath, packageName))
 	apiDuration := time.Since(apiStart).Seconds()
 
 	if err != nil {
@@ -550,7 +771,8 @@ func (gc *GHCRCollector) getPackageInfo(ctx context.Context, owner, repo, packag
 	return &packageInfo, nil
 }
 
-// makeGitHubAPIRequest makes a request to GitHub API, trying user endpoint first, then org endpoint
+// makeGitHubAPIRequest makes a request to the GitHub API, returning the
+// response only if it succeeded
 func (gc *GHCRCollector) makeGitHubAPIRequest(ctx context.Context, path string) (*http.Response, error) {
 	tracer := gc.app.GetTracer()
 
@@ -571,161 +793,118 @@ func (gc *GHCRCollector) makeGitHubAPIRequest(ctx context.Context, path string)
 		spanCtx = ctx
 	}
 
-	// Try user endpoint first
-	userURL := fmt.Sprintf("https://api.github.com%s", path)
-	slog.Debug("Making GitHub API request", "url", userURL, "path", path)
-
-	if collectorSpan != nil {
-		collectorSpan.AddEvent("user_endpoint_attempt",
-			attribute.String("url", userURL),
-		)
-	}
-
-	userReqStart := time.Now()
-
-	userReq, err := http.NewRequestWithContext(spanCtx, http.MethodGet, userURL, nil)
-	if err != nil {
+	if err := gc.rateLimit.check(); err != nil {
 		if collectorSpan != nil {
-			collectorSpan.RecordError(err, attribute.String("operation", "create-request"))
+			collectorSpan.RecordError(err, attribute.String("operation", "rate-limit-check"))
 		}
 
 		return nil, err
 	}
 
-	userReq.Header.Set("Accept", "application/vnd.github.v3+json")
+	requestURL := gc.apiBaseURL + path
+	slog.Debug("Making GitHub API request", "url", requestURL, "path", path)
 
-	if gc.token != "" {
-		userReq.Header.Set("Authorization", "Bearer "+gc.token)
-	}
-
-	userResp, err := gc.client.Do(userReq)
-	userReqDuration := time.Since(userReqStart).Seconds()
+	reqStart := time.Now()
+	resp, err := gc.doAPIRequest(spanCtx, requestURL)
+	reqDuration := time.Since(reqStart).Seconds()
 
 	if err != nil {
 		if collectorSpan != nil {
 			collectorSpan.SetAttributes(
-				attribute.Float64("user_endpoint.duration_seconds", userReqDuration),
+				attribute.Float64("api_request.duration_seconds", reqDuration),
 			)
-			collectorSpan.RecordError(err, attribute.String("operation", "user-endpoint-request"))
+			collectorSpan.RecordError(err, attribute.String("operation", "api-request"))
 		}
 
 		return nil, err
 	}
 
-	slog.Debug("GitHub API response", "url", userURL, "status_code", userResp.StatusCode)
+	slog.Debug("GitHub API response", "url", requestURL, "status_code", resp.StatusCode)
 
 	if collectorSpan != nil {
 		collectorSpan.SetAttributes(
-			attribute.Int("user_endpoint.status_code", userResp.StatusCode),
-			attribute.Float64("user_endpoint.duration_seconds", userReqDuration),
+			attribute.Int("api_request.status_code", resp.StatusCode),
+			attribute.Float64("api_request.duration_seconds", reqDuration),
 		)
 	}
 
-	// If user endpoint succeeds, return the response
-	if userResp.StatusCode == http.StatusOK {
-		if collectorSpan != nil {
-			collectorSpan.AddEvent("user_endpoint_success",
-				attribute.Int("status_code", userResp.StatusCode),
-			)
-		}
-
-		return userResp, nil
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
 	}
 
-	// If user endpoint returns 404, try org endpoint
-	if userResp.StatusCode == http.StatusNotFound {
-		if err := userResp.Body.Close(); err != nil {
-			slog.Error("Error closing user response body", "error", err)
-		}
-
-		if collectorSpan != nil {
-			collectorSpan.AddEvent("org_endpoint_attempt",
-				attribute.Int("user_endpoint_status", userResp.StatusCode),
-			)
-		}
-
-		// Replace /users/ with /orgs/ in the path
-		orgPath := strings.Replace(path, "/users/", "/orgs/", 1)
-		orgURL := fmt.Sprintf("https://api.github.com%s", orgPath)
-		slog.Debug("Trying org endpoint", "url", orgURL, "path", orgPath)
-
-		orgReqStart := time.Now()
-
-		orgReq, err := http.NewRequestWithContext(spanCtx, http.MethodGet, orgURL, nil)
-		if err != nil {
-			if collectorSpan != nil {
-				collectorSpan.RecordError(err, attribute.String("operation", "create-org-request"))
-			}
-
-			return nil, err
-		}
+	err = gc.apiError(resp)
 
-		orgReq.Header.Set("Accept", "application/vnd.github.v3+json")
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		slog.Error("Error closing response body", "error", closeErr)
+	}
 
-		if gc.token != "" {
-			orgReq.Header.Set("Authorization", "Bearer "+gc.token)
-		}
+	if collectorSpan != nil {
+		collectorSpan.RecordError(err, attribute.Int("status_code", resp.StatusCode))
+	}
 
-		orgResp, err := gc.client.Do(orgReq)
-		orgReqDuration := time.Since(orgReqStart).Seconds()
+	return nil, err
+}
 
-		if err != nil {
-			if collectorSpan != nil {
-				collectorSpan.SetAttributes(
-					attribute.Float64("org_endpoint.duration_seconds", orgReqDuration),
-				)
-				collectorSpan.RecordError(err, attribute.String("operation", "org-endpoint-request"))
-			}
+// doAPIRequest sends a single authenticated GET to the GitHub API. Requests
+// are made conditional on any cached ETag or Last-Modified value, and a 304
+// Not Modified reply is answered from the cache as if it were a fresh 200.
+func (gc *GHCRCollector) doAPIRequest(ctx context.Context, requestURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
 
-			return nil, err
-		}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-		slog.Debug("GitHub org API response", "url", orgURL, "status_code", orgResp.StatusCode)
+	if gc.token != "" {
+		req.Header.Set("Authorization", "Bearer "+gc.token)
+	}
 
-		if collectorSpan != nil {
-			collectorSpan.SetAttributes(
-				attribute.Int("org_endpoint.status_code", orgResp.StatusCode),
-				attribute.Float64("org_endpoint.duration_seconds", orgReqDuration),
-			)
-		}
+	cached, hasCached := gc.apiCache.get(requestURL)
+	if hasCached {
+		cached.setConditionalHeaders(req)
+	}
 
-		if orgResp.StatusCode == http.StatusOK {
-			if collectorSpan != nil {
-				collectorSpan.AddEvent("org_endpoint_success",
-					attribute.Int("status_code", orgResp.StatusCode),
-				)
-			}
+	resp, err := gc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
-			return orgResp, nil
-		}
+	gc.observeRateLimit(resp)
 
-		// If both fail, return the org endpoint error
-		if err := orgResp.Body.Close(); err != nil {
-			slog.Error("Error closing org response body", "error", err)
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing not modified response body", "error", err)
 		}
 
-		err = fmt.Errorf("API request failed with status %d", orgResp.StatusCode)
-		if collectorSpan != nil {
-			collectorSpan.RecordError(err, attribute.Int("status_code", orgResp.StatusCode))
-		}
+		slog.Debug("GitHub API response not modified, using cached body", "url", requestURL)
+		gc.metrics.APICacheHitsCounter.Inc()
 
-		return nil, err
+		return cached.response(req), nil
 	}
 
-	// If user endpoint fails with something other than 404, return that error
-	if err := userResp.Body.Close(); err != nil {
-		slog.Error("Error closing user response body", "error", err)
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
 	}
 
-	err = fmt.Errorf("API request failed with status %d", userResp.StatusCode)
-	if collectorSpan != nil {
-		collectorSpan.RecordError(err, attribute.Int("status_code", userResp.StatusCode))
+	gc.metrics.APICacheMissesCounter.Inc()
+
+	return gc.apiCache.store(requestURL, resp)
+}
+
+// apiError builds the error for a failed GitHub API response, distinguishing
+// rate limit rejections from other failures
+func (gc *GHCRCollector) apiError(resp *http.Response) error {
+	if rateLimitErr := gc.rateLimitError(resp); rateLimitErr != nil {
+		return rateLimitErr
 	}
 
-	return nil, err
+	return &APIError{StatusCode: resp.StatusCode}
 }
 
-func (gc *GHCRCollector) getPackageVersions(ctx context.Context, owner, repo, packageName string) ([]GHCRVersionResponse, error) {
+// getPackageVersions lists every version of a package, newest first. truncated
+// is true when github.max_pages cut the listing off before its oldest versions.
+func (gc *GHCRCollector) getPackageVersions(ctx context.Context, owner, repo, packageName string) ([]GHCRVersionResponse, bool, error) {
 	tracer := gc.app.GetTracer()
 
 	var (
@@ -746,55 +925,218 @@ func (gc *GHCRCollector) getPackageVersions(ctx context.Context, owner, repo, pa
 		spanCtx = ctx
 	}
 
+	ownerPath, err := gc.ownerPath(spanCtx, owner)
+	if err != nil {
+		if collectorSpan != nil {
+			collectorSpan.RecordError(err, attribute.String("operation", "resolve-owner-type"))
+		}
+
+		return nil, false, err
+	}
+
 	apiStart := time.Now()
-	resp, err := gc.makeGitHubAPIRequest(spanCtx, fmt.Sprintf("/users/%s/packages/container/%s/versions", owner, packageName))
+	path := fmt.Sprintf("%s/packages/container/%s/versions?per_page=%d", ownerPath, packageName, apiPageSize)
+	versions, pages, truncated, err := getPaginated[GHCRVersionResponse](spanCtx, gc, path)
 	apiDuration := time.Since(apiStart).Seconds()
 
 	if err != nil {
 		if collectorSpan != nil {
 			collectorSpan.SetAttributes(
 				attribute.Float64("api_request.duration_seconds", apiDuration),
+				attribute.Int("api_request.pages", pages),
 			)
 			collectorSpan.RecordError(err, attribute.String("operation", "api-request"))
 		}
 
-		return nil, err
+		return nil, false, err
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Error("Error closing response body", "error", err)
-		}
-	}()
-
-	decodeStart := time.Now()
-
-	var versions []GHCRVersionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		if collectorSpan != nil {
-			collectorSpan.RecordError(err, attribute.String("operation", "json-decode"))
-		}
-
-		return nil, err
-	}
-
-	decodeDuration := time.Since(decodeStart).Seconds()
-
 	if collectorSpan != nil {
 		collectorSpan.SetAttributes(
 			attribute.Float64("api_request.duration_seconds", apiDuration),
-			attribute.Float64("json_decode.duration_seconds", decodeDuration),
+			attribute.Int("api_request.pages", pages),
 			attribute.Int("package_versions.count", len(versions)),
+			attribute.Bool("package_versions.truncated", truncated),
 		)
 		collectorSpan.AddEvent("package_versions_decoded",
 			attribute.Int("count", len(versions)),
+			attribute.Int("pages", pages),
 		)
 	}
 
-	return versions, nil
+	return versions, truncated, nil
+}
+
+// getPaginated fetches a GitHub API listing, following Link rel="next"
+// headers until the last page or the configured page cap is reached. It
+// returns the combined items, the number of pages fetched and whether the
+// cap stopped it with a next page still to fetch.
+func getPaginated[T any](ctx context.Context, gc *GHCRCollector, path string) ([]T, int, bool, error) {
+	var (
+		items []T
+		pages int
+	)
+
+	maxPages := gc.config.GetMaxPages()
+
+	for path != "" {
+		if pages >= maxPages {
+			slog.Warn("Reached maximum number of pages, results are truncated",
+				"path", path,
+				"max_pages", maxPages,
+				"items", len(items))
+
+			return items, pages, true, nil
+		}
+
+		resp, err := gc.makeGitHubAPIRequest(ctx, path)
+		if err != nil {
+			return nil, pages, false, err
+		}
+
+		var page []T
+
+		err = json.NewDecoder(resp.Body).Decode(&page)
+
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Error("Error closing response body", "error", closeErr)
+		}
+
+		if err != nil {
+			return nil, pages, false, fmt.Errorf("failed to decode page %d: %w", pages+1, err)
+		}
+
+		pages++
+		items = append(items, page...)
+		path = gc.apiPath(nextPageURL(resp.Header.Get("Link")))
+	}
+
+	return items, pages, false, nil
 }
 
-func (gc *GHCRCollector) updatePackageMetrics(ctx context.Context, pkg config.PackageGroup, packageInfo *GHCRPackageResponse, versions []GHCRVersionResponse) {
+// nextPageURL extracts the rel="next" URL from a GitHub Link header
+func nextPageURL(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+
+	return ""
+}
+
+// apiPath converts an absolute API URL, such as one taken from a Link header,
+// into a path relative to the API base URL
+func (gc *GHCRCollector) apiPath(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(rawURL, gc.apiBaseURL) {
+		return strings.TrimPrefix(rawURL, gc.apiBaseURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		slog.Warn("Ignoring malformed pagination link", "url", rawURL, "error", err)
+		return ""
+	}
+
+	return parsed.RequestURI()
+}
+
+// removeStalePackages deletes the series of packages the group hasn't seen
+// for longer than the configured grace period. now is when this cycle marked
+// packages seen, so a zero grace period only removes the missing ones.
+func (gc *GHCRCollector) removeStalePackages(name, owner string, now time.Time) {
+	gracePeriod := gc.config.GetStaleGracePeriod()
+
+	for _, packageName := range gc.packages.expire(name, now, gracePeriod) {
+		slog.Info("Removing metrics for package that is no longer present",
+			"name", name,
+			"owner", owner,
+			"package", packageName,
+			"grace_period", gracePeriod)
+
+		gc.deletePackageMetrics(owner, packageName)
+	}
+}
+
+// deletePackageMetrics removes every per-package series for owner/repo
+func (gc *GHCRCollector) deletePackageMetrics(owner, repo string) {
+	labels := prometheus.Labels{
+		"owner": owner,
+		"repo":  repo,
+	}
+
+	for _, vec := range []*prometheus.GaugeVec{
+		gc.metrics.PackageDownloadsGauge,
+		gc.metrics.PackageDownloadStatsGauge,
+		gc.metrics.PackageLastPublishedGauge,
+		gc.metrics.PackagePublishOverdueSecondsGauge,
+		gc.metrics.PackagePublishOverdueGauge,
+		gc.metrics.PackageInfoGauge,
+		gc.metrics.PackageTaggedVersionsGauge,
+		gc.metrics.PackageUntaggedVersionsGauge,
+		gc.metrics.PackageOldestUntaggedVersionGauge,
+		gc.metrics.PackageVersionsTruncatedGauge,
+		gc.metrics.PackageTagInfoGauge,
+		gc.metrics.PackageTagUpdatedGauge,
+		gc.metrics.PackageLatestReleaseInfoGauge,
+		gc.metrics.PackageLatestStableTimestampGauge,
+		gc.metrics.PackageLatestPrereleaseTimestampGauge,
+		gc.metrics.PackageReleasesGauge,
+		gc.metrics.PackageChannelVersionsGauge,
+		gc.metrics.PackageChannelLastPublishedGauge,
+		gc.metrics.ImageSizeGauge,
+		gc.metrics.ImageLayersGauge,
+		gc.metrics.ImagePlatformInfoGauge,
+		gc.metrics.ImageMissingPlatformsGauge,
+		gc.metrics.ImageBuildInfoGauge,
+		gc.metrics.ImageCreatedTimestampGauge,
+		gc.metrics.ImagePublishDelayGauge,
+		gc.metrics.ImageSignedGauge,
+		gc.metrics.ImageSBOMPresentGauge,
+		gc.metrics.ImageProvenancePresentGauge,
+		gc.metrics.PackageStorageBytesGauge,
+		gc.metrics.PackageStorageSharedBytesGauge,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+
+	for _, vec := range []*prometheus.CounterVec{
+		gc.metrics.PackageVersionsPublishedCounter,
+		gc.metrics.PackageVersionsDeletedCounter,
+		gc.metrics.PackageTagMovesCounter,
+	} {
+		vec.DeletePartialMatch(labels)
+	}
+
+	gc.versions.forget(owner, repo)
+	gc.tags.forget(owner, repo)
+	gc.images.forget(owner, repo)
+	gc.storage.forget(owner, repo)
+	gc.apiCache.forgetPackage(owner, repo)
+}
+
+// updatePackageMetrics exports the metrics for a single package. Metrics
+// derived from the version list are only updated when haveVersions is true,
+// so a failed version listing doesn't look like every version disappeared.
+// truncated marks a listing cut off at github.max_pages before its oldest
+// versions.
+func (gc *GHCRCollector) updatePackageMetrics(ctx context.Context, pkg config.PackageGroup, packageInfo *GHCRPackageResponse, versions []GHCRVersionResponse, haveVersions, truncated bool) {
 	tracer := gc.app.GetTracer()
 
 	var (
@@ -835,6 +1177,8 @@ func (gc *GHCRCollector) updatePackageMetrics(ctx context.Context, pkg config.Pa
 
 	lastPublishedDuration := time.Since(lastPublishedStart).Seconds()
 
+	gc.updatePackageInfoMetric(pkg, packageInfo)
+
 	// Update package-level metrics
 	// Use version count as a proxy for activity (more versions = more activity)
 	gc.metrics.PackageDownloadsGauge.With(prometheus.Labels{
@@ -883,6 +1227,24 @@ func (gc *GHCRCollector) updatePackageMetrics(ctx context.Context, pkg config.Pa
 			"owner": pkg.Owner,
 			"repo":  pkg.Repo,
 		}).Set(float64(lastPublished.Unix()))
+
+		gc.updatePublishOverdueMetrics(pkg, lastPublished, time.Now())
+	}
+
+	if haveVersions {
+		tags := currentTags(versions)
+
+		gc.updateVersionMetrics(pkg, versions, truncated, time.Now())
+		gc.updateVersionEvents(pkg, versions, truncated)
+		gc.updateTagMetrics(pkg, tags)
+		gc.detectTagMoves(pkg, tags)
+		gc.updateReleaseMetrics(pkg, tags)
+		gc.updateChannelMetrics(pkg, versions)
+
+		if gc.config.Registry.Enabled {
+			gc.updateImageMetrics(spanCtx, pkg, tags)
+			gc.updateStorageMetrics(spanCtx, pkg, versions, truncated)
+		}
 	}
 
 	if collectorSpan != nil {
@@ -904,25 +1266,52 @@ func (gc *GHCRCollector) updatePackageMetrics(ctx context.Context, pkg config.Pa
 		"last_published", lastPublished.Format(time.RFC3339))
 }
 
-func (gc *GHCRCollector) retryWithBackoff(operation func() error, maxRetries int, initialDelay time.Duration) error {
-	var lastErr error
+// updatePackageInfoMetric exports the package's descriptive attributes,
+// replacing the previous series if any of them changed
+func (gc *GHCRCollector) updatePackageInfoMetric(pkg config.PackageGroup, packageInfo *GHCRPackageResponse) {
+	gc.metrics.PackageInfoGauge.DeletePartialMatch(prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	})
+
+	gc.metrics.PackageInfoGauge.With(prometheus.Labels{
+		"owner":        pkg.Owner,
+		"repo":         pkg.Repo,
+		"visibility":   packageInfo.Visibility,
+		"package_type": packageInfo.PackageType,
+		"repository":   packageInfo.Repository.FullName,
+		"html_url":     packageInfo.HTMLURL,
+	}).Set(1)
+}
 
-	delay := initialDelay
+// updatePublishOverdueMetrics reports how far past its expected publish
+// cadence a package is, for groups that set one
+func (gc *GHCRCollector) updatePublishOverdueMetrics(pkg config.PackageGroup, lastPublished, now time.Time) {
+	if pkg.PublishCadence.Duration <= 0 {
+		return
+	}
 
-	for i := 0; i <= maxRetries; i++ {
-		if err := operation(); err != nil {
-			lastErr = err
-			if i < maxRetries {
-				slog.Warn("Operation failed, retrying", "attempt", i+1, "error", err, "delay", delay)
-				time.Sleep(delay)
-				delay *= 2 // Exponential backoff
-			}
-		} else {
-			return nil
-		}
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
 	}
 
-	return fmt.Errorf("operation failed after %d retries: %w", maxRetries, lastErr)
+	overdue := max(now.Sub(lastPublished)-pkg.PublishCadence.Duration, 0)
+
+	gc.metrics.PackagePublishOverdueSecondsGauge.With(labels).Set(overdue.Seconds())
+
+	if overdue > 0 {
+		gc.metrics.PackagePublishOverdueGauge.With(labels).Set(1)
+
+		slog.Warn("Package publish is overdue",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"last_published", lastPublished.Format(time.RFC3339),
+			"publish_cadence", pkg.PublishCadence.Duration,
+			"overdue", overdue)
+	} else {
+		gc.metrics.PackagePublishOverdueGauge.With(labels).Set(0)
+	}
 }
 
 // getPackageDownloadStats scrapes the package page to get actual download statistics
@@ -930,7 +1319,7 @@ func (gc *GHCRCollector) getPackageDownloadStats(ctx context.Context, owner, pac
 	slog.Info("Starting download statistics collection", "owner", owner, "package", packageName)
 
 	// Construct the package page URL
-	packageURL := fmt.Sprintf("https://github.com/%s/%s/pkgs/container/%s", owner, packageName, packageName)
+	packageURL := fmt.Sprintf("%s/%s/%s/pkgs/container/%s", gc.webBaseURL, owner, packageName, packageName)
 	slog.Debug("Constructed package URL", "url", packageURL)
 
 	// Create request to the package page
@@ -1098,30 +1487,17 @@ func (gc *GHCRCollector) getPackageDownloadStats(ctx context.Context, owner, pac
 func (gc *GHCRCollector) getOwnerPackages(ctx context.Context, owner string) ([]GHCRPackageResponse, error) {
 	slog.Info("Getting packages for owner", "owner", owner)
 
-	// Try user endpoint first
-	resp, err := gc.makeGitHubAPIRequest(ctx, fmt.Sprintf("/users/%s/packages?package_type=container", owner))
+	ownerPath, err := gc.ownerPath(ctx, owner)
 	if err != nil {
-		// If user endpoint fails, try org endpoint
-		slog.Debug("User endpoint failed, trying org endpoint", "owner", owner, "error", err)
-
-		resp, err = gc.makeGitHubAPIRequest(ctx, fmt.Sprintf("/orgs/%s/packages?package_type=container", owner))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get packages for owner %s: %w", owner, err)
-		}
+		return nil, err
 	}
 
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			slog.Error("Error closing response body", "error", err)
-		}
-	}()
-
-	var packages []GHCRPackageResponse
-	if err := json.NewDecoder(resp.Body).Decode(&packages); err != nil {
-		return nil, fmt.Errorf("failed to decode packages response: %w", err)
+	packages, pages, _, err := getPaginated[GHCRPackageResponse](ctx, gc, fmt.Sprintf("%s/packages?package_type=container&per_page=%d", ownerPath, apiPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get packages for owner %s: %w", owner, err)
 	}
 
-	slog.Info("Retrieved packages for owner", "owner", owner, "package_count", len(packages))
+	slog.Info("Retrieved packages for owner", "owner", owner, "package_count", len(packages), "pages", pages)
 
 	return packages, nil
 }
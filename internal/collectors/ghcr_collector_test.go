@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -18,6 +19,7 @@ import (
 	"github.com/d0ugal/promexporter/app"
 	promexporter_metrics "github.com/d0ugal/promexporter/metrics"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestNewGHCRCollector(t *testing.T) {
@@ -264,6 +266,7 @@ func TestGetPackageDownloadStatsHTTPError(t *testing.T) {
 
 	collector := NewGHCRCollector(cfg, registry, testApp)
 	collector.client = server.Client()
+	collector.webBaseURL = server.URL
 
 	// Test that we get an error when the HTTP request fails
 	_, err := collector.getPackageDownloadStats(context.Background(), "test-owner", "test-package")
@@ -276,3 +279,316 @@ func TestGetPackageDownloadStatsHTTPError(t *testing.T) {
 		t.Errorf("Expected error '%s', got '%s'", expectedError, err.Error())
 	}
 }
+
+func TestFilterOwnerPackages(t *testing.T) {
+	group := config.PackageGroup{
+		Owner:      "d0ugal",
+		Exclude:    []string{"ci-*"},
+		Visibility: []string{"public"},
+	}
+
+	packages := []GHCRPackageResponse{
+		{Name: "filesystem-exporter", Visibility: "public"},
+		{Name: "ci-cache", Visibility: "public"},
+		{Name: "ci-builder", Visibility: "public"},
+		{Name: "secret-exporter", Visibility: "private"},
+		{Name: "MQTT-Exporter", Visibility: "public"},
+	}
+
+	// mqtt-exporter has its own group, so the owner-wide group leaves it alone
+	cfg := &config.Config{Packages: []config.PackageGroup{group, {Owner: "d0ugal", Repo: "mqtt-exporter"}}}
+
+	selected, filtered := filterOwnerPackages(cfg, group, packages)
+
+	if len(selected) != 1 || selected[0].Name != "filesystem-exporter" {
+		t.Fatalf("Expected only filesystem-exporter to be selected, got %+v", selected)
+	}
+
+	if filtered[config.FilterReasonConfigured] != 1 {
+		t.Errorf("Expected 1 package skipped for its own group, got %d", filtered[config.FilterReasonConfigured])
+	}
+
+	if filtered[config.FilterReasonExclude] != 2 {
+		t.Errorf("Expected 2 packages excluded, got %d", filtered[config.FilterReasonExclude])
+	}
+
+	if filtered[config.FilterReasonVisibility] != 1 {
+		t.Errorf("Expected 1 package filtered by visibility, got %d", filtered[config.FilterReasonVisibility])
+	}
+}
+
+// newTestCollector builds a collector wired to a fresh registry and pointing
+// at the given test server for GitHub API requests
+func newTestCollector(t *testing.T, cfg *config.Config, server *httptest.Server) *GHCRCollector {
+	t.Helper()
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	baseRegistry := promexporter_metrics.NewRegistry("test_exporter_info")
+	registry := metrics.NewGHCRRegistry(baseRegistry)
+
+	testApp := app.New("Test Exporter").
+		WithConfig(&cfg.BaseConfig).
+		WithMetrics(baseRegistry).
+		Build()
+
+	collector := NewGHCRCollector(cfg, registry, testApp)
+	collector.client = server.Client()
+	collector.apiBaseURL = server.URL
+	collector.webBaseURL = server.URL
+	collector.registry = newRegistryClient(server.Client(), server.URL, "test-token")
+
+	// Test servers serve d0ugal's packages under /users/, so skip the lookup
+	collector.ownerTypes.set("d0ugal", config.OwnerTypeUser)
+
+	return collector
+}
+
+// newMetricsTestCollector returns a collector for tests that only exercise
+// metric updates and never reach the GitHub API
+func newMetricsTestCollector(t *testing.T) *GHCRCollector {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	return newTestCollector(t, &config.Config{}, server)
+}
+
+// newPaginatedVersionsServer serves totalPages pages of package versions,
+// linking each page to the next the way the GitHub API does
+func newPaginatedVersionsServer(t *testing.T, totalPages int) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/d0ugal/packages/container/filesystem-exporter/versions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("Expected per_page=100, got %q", r.URL.Query().Get("per_page"))
+		}
+
+		page := 1
+		if p := r.URL.Query().Get("page"); p != "" {
+			page, _ = strconv.Atoi(p)
+		}
+
+		if page < totalPages {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=%d>; rel="next", <%s%s?per_page=100&page=%d>; rel="last"`,
+				server.URL, r.URL.Path, page+1, server.URL, r.URL.Path, totalPages))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `[{"id": %d, "created_at": "2025-10-%02dT12:00:00Z"}, {"id": %d, "created_at": "2025-09-01T12:00:00Z"}]`,
+			page*10, page, page*10+1)
+	}))
+
+	return server
+}
+
+func TestGetPackageVersionsFollowsPagination(t *testing.T) {
+	server := newPaginatedVersionsServer(t, 3)
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	versions, truncated, err := collector.getPackageVersions(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if truncated {
+		t.Error("Expected a listing fetched to its last page not to be truncated")
+	}
+
+	if len(versions) != 6 {
+		t.Fatalf("Expected 6 versions across 3 pages, got %d", len(versions))
+	}
+
+	if versions[4].ID != 30 {
+		t.Errorf("Expected the newest version from the last page to be included, got ID %d", versions[4].ID)
+	}
+}
+
+func TestGetPackageVersionsMaxPages(t *testing.T) {
+	server := newPaginatedVersionsServer(t, 5)
+	defer server.Close()
+
+	cfg := &config.Config{GitHub: config.GitHubConfig{MaxPages: 2}}
+	collector := newTestCollector(t, cfg, server)
+
+	versions, truncated, err := collector.getPackageVersions(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(versions) != 4 {
+		t.Errorf("Expected pagination to stop after 2 pages (4 versions), got %d", len(versions))
+	}
+
+	if !truncated {
+		t.Error("Expected a listing stopped with pages left to be truncated")
+	}
+}
+
+func TestGetPackageVersionsLastPageAtMaxPages(t *testing.T) {
+	server := newPaginatedVersionsServer(t, 2)
+	defer server.Close()
+
+	cfg := &config.Config{GitHub: config.GitHubConfig{MaxPages: 2}}
+	collector := newTestCollector(t, cfg, server)
+
+	// Using up every allowed page only truncates when another page follows
+	_, truncated, err := collector.getPackageVersions(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if truncated {
+		t.Error("Expected a listing whose last page is the page cap not to be truncated")
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected string
+	}{
+		{
+			header:   `<https://api.github.com/user/1/packages?page=2>; rel="next", <https://api.github.com/user/1/packages?page=5>; rel="last"`,
+			expected: "https://api.github.com/user/1/packages?page=2",
+		},
+		{
+			header:   `<https://api.github.com/user/1/packages?page=1>; rel="prev", <https://api.github.com/user/1/packages?page=1>; rel="first"`,
+			expected: "",
+		},
+		{
+			header:   "",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := nextPageURL(tc.header); got != tc.expected {
+			t.Errorf("nextPageURL(%q) = %q, expected %q", tc.header, got, tc.expected)
+		}
+	}
+}
+
+func TestCollectOwnerPackagesFollowsPagination(t *testing.T) {
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/d0ugal/packages" || r.URL.Query().Get("package_type") != "container" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"name": "mqtt-exporter", "visibility": "public"}]`))
+			return
+		}
+
+		w.Header().Set("Link", fmt.Sprintf(`<%s/users/d0ugal/packages?package_type=container&per_page=100&page=2>; rel="next"`, server.URL))
+		_, _ = w.Write([]byte(`[{"name": "filesystem-exporter", "visibility": "public"}, {"name": "ci-cache", "visibility": "public"}]`))
+	}))
+	defer server.Close()
+
+	// Exclude everything so only discovery is exercised
+	group := config.PackageGroup{Owner: "d0ugal", Exclude: []string{"*"}}
+	cfg := &config.Config{Packages: []config.PackageGroup{group}}
+	collector := newTestCollector(t, cfg, server)
+
+	collector.collectOwnerPackages(context.Background(), group.GetName(), group)
+
+	discovered := testutil.ToFloat64(collector.metrics.OwnerPackagesDiscoveredGauge.With(prometheus.Labels{"owner": "d0ugal"}))
+	if discovered != 3 {
+		t.Errorf("Expected 3 discovered packages across both pages, got %f", discovered)
+	}
+
+	excluded := testutil.ToFloat64(collector.metrics.OwnerPackagesFilteredGauge.With(prometheus.Labels{
+		"owner":  "d0ugal",
+		"reason": config.FilterReasonExclude,
+	}))
+	if excluded != 3 {
+		t.Errorf("Expected 3 excluded packages, got %f", excluded)
+	}
+}
+
+func TestUpdatePackageInfoMetric(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+
+	packageInfo := &GHCRPackageResponse{
+		Name:        "mqtt-exporter",
+		PackageType: "container",
+		Visibility:  "private",
+		HTMLURL:     "https://github.com/users/d0ugal/packages/container/package/mqtt-exporter",
+	}
+	packageInfo.Repository.FullName = "d0ugal/mqtt-exporter"
+
+	collector.updatePackageInfoMetric(pkg, packageInfo)
+
+	// The package is made public
+	packageInfo.Visibility = "public"
+	collector.updatePackageInfoMetric(pkg, packageInfo)
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageInfoGauge); got != 1 {
+		t.Fatalf("Expected a single package info series, got %d", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageInfoGauge.With(prometheus.Labels{
+		"owner":        "d0ugal",
+		"repo":         "mqtt-exporter",
+		"visibility":   "public",
+		"package_type": "container",
+		"repository":   "d0ugal/mqtt-exporter",
+		"html_url":     "https://github.com/users/d0ugal/packages/container/package/mqtt-exporter",
+	})); got != 1 {
+		t.Errorf("Expected package info with the new visibility, got %v", got)
+	}
+}
+
+func TestUpdatePublishOverdueMetrics(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+	now := time.Date(2026, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	// Without a cadence nothing is exported
+	collector.updatePublishOverdueMetrics(config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}, now.Add(-72*time.Hour), now)
+
+	if got := testutil.CollectAndCount(collector.metrics.PackagePublishOverdueGauge); got != 0 {
+		t.Fatalf("Expected no overdue series without a publish cadence, got %d", got)
+	}
+
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", PublishCadence: config.Duration{Duration: 26 * time.Hour}}
+
+	testCases := []struct {
+		description   string
+		lastPublished time.Time
+		overdue       float64
+		seconds       float64
+	}{
+		{description: "On time", lastPublished: now.Add(-25 * time.Hour), overdue: 0, seconds: 0},
+		{description: "Overdue", lastPublished: now.Add(-30 * time.Hour), overdue: 1, seconds: (4 * time.Hour).Seconds()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			collector.updatePublishOverdueMetrics(pkg, tc.lastPublished, now)
+
+			if got := testutil.ToFloat64(collector.metrics.PackagePublishOverdueGauge.With(labels)); got != tc.overdue {
+				t.Errorf("Expected overdue to be %v, got %v", tc.overdue, got)
+			}
+
+			if got := testutil.ToFloat64(collector.metrics.PackagePublishOverdueSecondsGauge.With(labels)); got != tc.seconds {
+				t.Errorf("Expected %v overdue seconds, got %v", tc.seconds, got)
+			}
+		})
+	}
+}
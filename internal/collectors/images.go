@@ -0,0 +1,331 @@
+package collectors
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"ghcr-exporter/internal/config"
+	"github.com/d0ugal/promexporter/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// imageCache keeps the images fetched for each package by manifest digest.
+// Manifests are immutable, so an image is only fetched again once its tag
+// moves to a new digest. It also remembers the tags each package's image
+// series were exported for, so series of removed tags can be deleted.
+type imageCache struct {
+	mu     sync.Mutex
+	images map[string]map[string]*ociImage // owner/repo -> digest -> image
+	tags   map[string]map[string]struct{}  // owner/repo -> tracked tags
+}
+
+func newImageCache() *imageCache {
+	return &imageCache{
+		images: make(map[string]map[string]*ociImage),
+		tags:   make(map[string]map[string]struct{}),
+	}
+}
+
+func (c *imageCache) get(owner, repo, digest string) (*ociImage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	image, ok := c.images[owner+"/"+repo][digest]
+
+	return image, ok
+}
+
+// replace swaps the package's cached images for those used in this cycle,
+// so images no longer tagged are dropped
+func (c *imageCache) replace(owner, repo string, images map[string]*ociImage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.images[owner+"/"+repo] = images
+}
+
+// swapTags records the package's tracked tags for this cycle and returns
+// those of the previous cycle
+func (c *imageCache) swapTags(owner, repo string, tags map[string]struct{}) map[string]struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := owner + "/" + repo
+	previous := c.tags[key]
+	c.tags[key] = tags
+
+	return previous
+}
+
+// forget drops the cached images of a package that is no longer collected
+func (c *imageCache) forget(owner, repo string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.images, owner+"/"+repo)
+	delete(c.tags, owner+"/"+repo)
+}
+
+// collectImages fetches the image each tracked tag points to, reusing cached
+// images for digests that haven't changed. Tags whose image can't be fetched
+// are left out.
+func (gc *GHCRCollector) collectImages(ctx context.Context, pkg config.PackageGroup, tags map[string]tagTarget) map[string]*ociImage {
+	tracer := gc.app.GetTracer()
+
+	var (
+		collectorSpan *tracing.CollectorSpan
+		spanCtx       context.Context //nolint:contextcheck // Extracting context from span for child operations
+	)
+
+	if tracer != nil && tracer.IsEnabled() {
+		collectorSpan = tracer.NewCollectorSpan(ctx, "ghcr-collector", "collect-images")
+		collectorSpan.SetAttributes(
+			attribute.String("package.owner", pkg.Owner),
+			attribute.String("package.repo", pkg.Repo),
+		)
+
+		spanCtx = collectorSpan.Context()
+		defer collectorSpan.End()
+	} else {
+		spanCtx = ctx
+	}
+
+	repository := registryRepository(pkg.Owner, pkg.Repo)
+	images := make(map[string]*ociImage)
+	byDigest := make(map[string]*ociImage)
+	fetched := 0
+
+	for tag, target := range tags {
+		if !pkg.TracksTag(tag) {
+			continue
+		}
+
+		// Container versions are named after their manifest digest
+		reference := tag
+		if strings.HasPrefix(target.digest, "sha256:") {
+			reference = target.digest
+		}
+
+		image, ok := byDigest[reference]
+		if !ok {
+			image, ok = gc.images.get(pkg.Owner, pkg.Repo, reference)
+		}
+
+		if !ok {
+			var err error
+
+			image, err = gc.registry.getImage(spanCtx, repository, reference)
+			if err != nil {
+				slog.Warn("Failed to get image", "owner", pkg.Owner, "package", pkg.Repo, "tag", tag, "error", err)
+
+				if collectorSpan != nil {
+					collectorSpan.RecordError(err, attribute.String("tag", tag))
+				}
+
+				continue
+			}
+
+			fetched++
+		}
+
+		images[tag] = image
+		byDigest[reference] = image
+	}
+
+	gc.images.replace(pkg.Owner, pkg.Repo, byDigest)
+
+	if collectorSpan != nil {
+		collectorSpan.SetAttributes(
+			attribute.Int("images.count", len(images)),
+			attribute.Int("images.fetched", fetched),
+		)
+	}
+
+	slog.Debug("Collected images", "owner", pkg.Owner, "package", pkg.Repo, "images", len(images), "fetched", fetched)
+
+	return images
+}
+
+// platformLabel returns the platform label for an image, which is unknown
+// when the registry didn't say
+func platformLabel(platformImage ociPlatformImage) string {
+	if platformImage.platform == "" {
+		return "unknown"
+	}
+
+	return platformImage.platform
+}
+
+// updateImageMetrics exports metrics read from the images of tracked tags. A
+// tag whose image can't be fetched keeps the series from its last successful
+// fetch, so alerts on them don't stop evaluating during a registry outage.
+// Series are only removed once their tag is gone or no longer tracked.
+func (gc *GHCRCollector) updateImageMetrics(ctx context.Context, pkg config.PackageGroup, tags map[string]tagTarget) {
+	images := gc.collectImages(ctx, pkg, tags)
+
+	tracked := make(map[string]struct{})
+
+	for tag := range tags {
+		if pkg.TracksTag(tag) {
+			tracked[tag] = struct{}{}
+		}
+	}
+
+	for tag := range gc.images.swapTags(pkg.Owner, pkg.Repo, tracked) {
+		if _, ok := tracked[tag]; !ok {
+			gc.deleteImageSeries(pkg, tag, true)
+		}
+	}
+
+	for tag, image := range images {
+		// Signature series are left for updateSupplyChainMetrics, which keeps
+		// them when their lookup fails
+		gc.deleteImageSeries(pkg, tag, false)
+		gc.updateSizeMetrics(pkg, tag, image)
+		gc.updatePlatformMetrics(pkg, tag, image)
+		gc.updateBuildInfoMetrics(pkg, tag, image)
+		gc.updatePublishDelayMetric(pkg, tag, tags[tag], image)
+	}
+
+	gc.updateSupplyChainMetrics(ctx, pkg, images)
+}
+
+// deleteImageSeries removes the image series of a tag, including whether it's
+// signed and attested when supplyChain is true
+func (gc *GHCRCollector) deleteImageSeries(pkg config.PackageGroup, tag string, supplyChain bool) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+		"tag":   tag,
+	}
+
+	vecs := []*prometheus.GaugeVec{
+		gc.metrics.ImageSizeGauge,
+		gc.metrics.ImageLayersGauge,
+		gc.metrics.ImagePlatformInfoGauge,
+		gc.metrics.ImageMissingPlatformsGauge,
+		gc.metrics.ImageBuildInfoGauge,
+		gc.metrics.ImageCreatedTimestampGauge,
+		gc.metrics.ImagePublishDelayGauge,
+	}
+
+	if supplyChain {
+		vecs = append(vecs,
+			gc.metrics.ImageSignedGauge,
+			gc.metrics.ImageSBOMPresentGauge,
+			gc.metrics.ImageProvenancePresentGauge,
+		)
+	}
+
+	for _, vec := range vecs {
+		vec.DeletePartialMatch(labels)
+	}
+}
+
+// updateSizeMetrics exports the compressed size and layer count of each
+// platform of an image
+func (gc *GHCRCollector) updateSizeMetrics(pkg config.PackageGroup, tag string, image *ociImage) {
+	for _, platformImage := range image.platforms {
+		labels := prometheus.Labels{
+			"owner":    pkg.Owner,
+			"repo":     pkg.Repo,
+			"tag":      tag,
+			"platform": platformLabel(platformImage),
+		}
+
+		size := platformImage.manifest.Config.Size
+		for _, layer := range platformImage.manifest.Layers {
+			size += layer.Size
+		}
+
+		gc.metrics.ImageSizeGauge.With(labels).Set(float64(size))
+		gc.metrics.ImageLayersGauge.With(labels).Set(float64(len(platformImage.manifest.Layers)))
+	}
+}
+
+// updatePlatformMetrics exports the platforms an image was built for and how
+// many of the group's required platforms it lacks
+func (gc *GHCRCollector) updatePlatformMetrics(pkg config.PackageGroup, tag string, image *ociImage) {
+	platforms := make([]string, 0, len(image.platforms))
+
+	for _, platformImage := range image.platforms {
+		platform := platformLabel(platformImage)
+		platforms = append(platforms, platform)
+
+		gc.metrics.ImagePlatformInfoGauge.With(prometheus.Labels{
+			"owner":    pkg.Owner,
+			"repo":     pkg.Repo,
+			"tag":      tag,
+			"platform": platform,
+		}).Set(1)
+	}
+
+	if len(pkg.Platforms) == 0 {
+		return
+	}
+
+	missing := pkg.MissingPlatforms(platforms)
+
+	gc.metrics.ImageMissingPlatformsGauge.With(prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+		"tag":   tag,
+	}).Set(float64(len(missing)))
+
+	if len(missing) > 0 {
+		slog.Warn("Image is missing required platforms",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"tag", tag,
+			"missing", missing,
+			"platforms", platforms)
+	}
+}
+
+// updateBuildInfoMetrics exports the revision, version and source an image
+// was built from and when it was built
+func (gc *GHCRCollector) updateBuildInfoMetrics(pkg config.PackageGroup, tag string, image *ociImage) {
+	revision := image.annotation(annotationRevision)
+	version := image.annotation(annotationVersion)
+	source := image.annotation(annotationSource)
+
+	if revision != "" || version != "" || source != "" {
+		gc.metrics.ImageBuildInfoGauge.With(prometheus.Labels{
+			"owner":    pkg.Owner,
+			"repo":     pkg.Repo,
+			"tag":      tag,
+			"revision": revision,
+			"version":  version,
+			"source":   source,
+		}).Set(1)
+	}
+
+	if created := image.created(); !created.IsZero() {
+		gc.metrics.ImageCreatedTimestampGauge.With(prometheus.Labels{
+			"owner": pkg.Owner,
+			"repo":  pkg.Repo,
+			"tag":   tag,
+		}).Set(float64(created.Unix()))
+	}
+}
+
+// updatePublishDelayMetric exports how long an image waited between being
+// built and its version being published. Clock skew between the build host
+// and GitHub can make the delay negative, so it's reported as zero.
+func (gc *GHCRCollector) updatePublishDelayMetric(pkg config.PackageGroup, tag string, target tagTarget, image *ociImage) {
+	created := image.created()
+	if created.IsZero() || target.created.IsZero() {
+		return
+	}
+
+	delay := max(target.created.Sub(created), 0)
+
+	gc.metrics.ImagePublishDelayGauge.With(prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+		"tag":   tag,
+	}).Set(delay.Seconds())
+}
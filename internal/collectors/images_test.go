@@ -0,0 +1,199 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// newImageTestCollector returns a collector reading images from registry
+func newImageTestCollector(t *testing.T, registry *testRegistry) *GHCRCollector {
+	t.Helper()
+
+	collector := newMetricsTestCollector(t)
+	collector.registry = registry.client("")
+
+	return collector
+}
+
+// imageVersion returns a package version for an image, named after its
+// digest the way the GitHub API does
+func imageVersion(id int, image ociDescriptor, tags ...string) GHCRVersionResponse {
+	version := testVersion(id, "2026-10-01T12:00:00Z", tags...)
+	version.Name = image.Digest
+
+	return version
+}
+
+func TestUpdateImageMetricsSize(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 1000, 2000)
+	arm64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 1500)
+	index := registry.addIndex(repository, []ociDescriptor{amd64, arm64}, "v1.0.0")
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", Tags: []string{"v*"}}
+	tags := currentTags([]GHCRVersionResponse{imageVersion(1, index, "v1.0.0", "sha-abc123")})
+
+	collector.updateImageMetrics(context.Background(), pkg, tags)
+
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0", "platform": "linux/amd64"}
+
+	manifest, _, err := collector.registry.getManifest(context.Background(), repository, amd64.Digest)
+	if err != nil {
+		t.Fatalf("Failed to get manifest: %v", err)
+	}
+
+	if got, expected := testutil.ToFloat64(collector.metrics.ImageSizeGauge.With(labels)), float64(manifest.Config.Size+3000); got != expected {
+		t.Errorf("Expected the amd64 size to be %v including its config, got %v", expected, got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.ImageLayersGauge.With(labels)); got != 2 {
+		t.Errorf("Expected 2 amd64 layers, got %v", got)
+	}
+
+	// Only the tracked tag is exported, once per platform
+	if got := testutil.CollectAndCount(collector.metrics.ImageSizeGauge); got != 2 {
+		t.Errorf("Expected 2 size series, got %d", got)
+	}
+
+	// Unchanged digests are served from the cache
+	requests := registry.manifestRequests.Load()
+	collector.updateImageMetrics(context.Background(), pkg, tags)
+
+	if got := registry.manifestRequests.Load(); got != requests {
+		t.Errorf("Expected cached images not to be fetched again, got %d more manifest requests", got-requests)
+	}
+}
+
+func TestUpdateImageMetricsPlatforms(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 100)
+	arm64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64", Variant: "v8"}, ociImageConfig{}, 100)
+	complete := registry.addIndex(repository, []ociDescriptor{amd64, arm64})
+	amd64Only := registry.addIndex(repository, []ociDescriptor{amd64})
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", Platforms: []string{"linux/amd64", "linux/arm64"}}
+
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, complete, "v1.0.0"),
+		imageVersion(2, amd64Only, "v1.1.0"),
+	}))
+
+	for tag, expected := range map[string]float64{"v1.0.0": 0, "v1.1.0": 1} {
+		if got := testutil.ToFloat64(collector.metrics.ImageMissingPlatformsGauge.With(prometheus.Labels{
+			"owner": "d0ugal", "repo": "mqtt-exporter", "tag": tag,
+		})); got != expected {
+			t.Errorf("Expected %s to be missing %v platforms, got %v", tag, expected, got)
+		}
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.ImagePlatformInfoGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0", "platform": "linux/arm64/v8",
+	})); got != 1 {
+		t.Errorf("Expected platform info for linux/arm64/v8, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImagePlatformInfoGauge); got != 3 {
+		t.Errorf("Expected 3 platform info series, got %d", got)
+	}
+}
+
+func TestUpdateImageMetricsBuildInfo(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	imageConfig := ociImageConfig{Created: "2026-10-01T11:00:00Z"}
+	imageConfig.Config.Labels = map[string]string{
+		annotationRevision: "label-revision",
+		annotationVersion:  "1.0.0",
+		annotationSource:   "https://github.com/d0ugal/mqtt-exporter",
+	}
+
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, imageConfig, 100)
+	index := registry.addManifest(repository, ociManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeOCIIndex,
+		Manifests:     []ociDescriptor{amd64},
+		Annotations: map[string]string{
+			annotationRevision: "0123456789abcdef",
+			annotationCreated:  "2026-10-01T10:00:00Z",
+		},
+	})
+	unlabelled := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 100)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, index, "v1.0.0"),
+		imageVersion(2, unlabelled, "dev"),
+	}))
+
+	// Index annotations take precedence over config labels
+	if got := testutil.ToFloat64(collector.metrics.ImageBuildInfoGauge.With(prometheus.Labels{
+		"owner":    "d0ugal",
+		"repo":     "mqtt-exporter",
+		"tag":      "v1.0.0",
+		"revision": "0123456789abcdef",
+		"version":  "1.0.0",
+		"source":   "https://github.com/d0ugal/mqtt-exporter",
+	})); got != 1 {
+		t.Errorf("Expected build info for v1.0.0, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImageBuildInfoGauge); got != 1 {
+		t.Errorf("Expected no build info for an image without metadata, got %d series", got)
+	}
+
+	// The created annotation takes precedence over the config's created time
+	created := parseVersionTime("2026-10-01T10:00:00Z")
+	if got := testutil.ToFloat64(collector.metrics.ImageCreatedTimestampGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0",
+	})); got != float64(created.Unix()) {
+		t.Errorf("Expected the created annotation %v, got %v", created.Unix(), got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImageCreatedTimestampGauge); got != 1 {
+		t.Errorf("Expected only v1.0.0 to have a created timestamp, got %d series", got)
+	}
+}
+
+func TestUpdateImageMetricsPublishDelay(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	// Versions from testVersion are created at 2025-10-01T12:00:00Z
+	stale := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{Created: "2025-10-01T10:30:00Z"}, 100)
+	skewed := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{Created: "2025-10-01T12:05:00Z"}, 200)
+	unknown := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 300)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, stale, "v1.0.0"),
+		imageVersion(2, skewed, "v1.1.0"),
+		imageVersion(3, unknown, "v1.2.0"),
+	}))
+
+	for tag, expected := range map[string]float64{"v1.0.0": 90 * 60, "v1.1.0": 0} {
+		if got := testutil.ToFloat64(collector.metrics.ImagePublishDelayGauge.With(prometheus.Labels{
+			"owner": "d0ugal", "repo": "mqtt-exporter", "tag": tag,
+		})); got != expected {
+			t.Errorf("Expected a %v second publish delay for %s, got %v", expected, tag, got)
+		}
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImagePublishDelayGauge); got != 2 {
+		t.Errorf("Expected no publish delay for an image without a created time, got %d series", got)
+	}
+}
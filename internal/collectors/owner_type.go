@@ -0,0 +1,105 @@
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"ghcr-exporter/internal/config"
+)
+
+// githubOwnerResponse is the subset of /users/{owner} needed to tell users
+// and organizations apart
+type githubOwnerResponse struct {
+	Login string `json:"login"`
+	Type  string `json:"type"`
+}
+
+// ownerTypeCache remembers whether each owner is a user or an organization
+// so package requests go straight to the right endpoint
+type ownerTypeCache struct {
+	mu    sync.Mutex
+	types map[string]string
+}
+
+func newOwnerTypeCache(packages []config.PackageGroup) *ownerTypeCache {
+	cache := &ownerTypeCache{
+		types: make(map[string]string),
+	}
+
+	// Explicitly configured owner types never need resolving
+	for _, group := range packages {
+		if group.OwnerType != "" {
+			cache.set(group.Owner, group.OwnerType)
+		}
+	}
+
+	return cache
+}
+
+func (c *ownerTypeCache) get(owner string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ownerType, ok := c.types[strings.ToLower(owner)]
+
+	return ownerType, ok
+}
+
+func (c *ownerTypeCache) set(owner, ownerType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.types[strings.ToLower(owner)] = ownerType
+}
+
+// resolveOwnerType returns whether owner is a user or an organization,
+// asking the GitHub API the first time an unconfigured owner is seen
+func (gc *GHCRCollector) resolveOwnerType(ctx context.Context, owner string) (string, error) {
+	if ownerType, ok := gc.ownerTypes.get(owner); ok {
+		return ownerType, nil
+	}
+
+	resp, err := gc.makeGitHubAPIRequest(ctx, "/users/"+owner)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve owner type for %s: %w", owner, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing response body", "error", err)
+		}
+	}()
+
+	var ownerInfo githubOwnerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ownerInfo); err != nil {
+		return "", fmt.Errorf("failed to decode owner %s: %w", owner, err)
+	}
+
+	ownerType := config.OwnerTypeUser
+	if ownerInfo.Type == "Organization" {
+		ownerType = config.OwnerTypeOrg
+	}
+
+	slog.Info("Resolved owner type", "owner", owner, "github_type", ownerInfo.Type, "owner_type", ownerType)
+	gc.ownerTypes.set(owner, ownerType)
+
+	return ownerType, nil
+}
+
+// ownerPath returns the API path prefix for packages belonging to owner
+func (gc *GHCRCollector) ownerPath(ctx context.Context, owner string) (string, error) {
+	ownerType, err := gc.resolveOwnerType(ctx, owner)
+	if err != nil {
+		return "", err
+	}
+
+	if ownerType == config.OwnerTypeOrg {
+		return "/orgs/" + owner, nil
+	}
+
+	return "/users/" + owner, nil
+}
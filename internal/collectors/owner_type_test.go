@@ -0,0 +1,76 @@
+package collectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"ghcr-exporter/internal/config"
+)
+
+func TestResolveOwnerTypeOnce(t *testing.T) {
+	var ownerLookups, userRequests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/users/home-assistant":
+			ownerLookups.Add(1)
+			_, _ = w.Write([]byte(`{"login": "home-assistant", "type": "Organization"}`))
+		case "/orgs/home-assistant/packages/container/home-assistant":
+			_, _ = w.Write([]byte(`{"name": "home-assistant", "version_count": 42}`))
+		default:
+			userRequests.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	for range 3 {
+		info, err := collector.getPackageInfo(context.Background(), "home-assistant", "home-assistant", "home-assistant")
+		if err != nil {
+			t.Fatalf("Expected package info from the org endpoint, got: %v", err)
+		}
+
+		if info.VersionCount != 42 {
+			t.Errorf("Expected version count 42, got %d", info.VersionCount)
+		}
+	}
+
+	if got := ownerLookups.Load(); got != 1 {
+		t.Errorf("Expected the owner type to be looked up once, got %d lookups", got)
+	}
+
+	if got := userRequests.Load(); got != 0 {
+		t.Errorf("Expected no requests to user package endpoints, got %d", got)
+	}
+}
+
+func TestConfiguredOwnerTypeSkipsLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/packages/container/widget" {
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "widget", "version_count": 1}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Packages: []config.PackageGroup{{Owner: "Acme", OwnerType: config.OwnerTypeOrg, Repo: "widget"}},
+	}
+	collector := newTestCollector(t, cfg, server)
+
+	if _, err := collector.getPackageInfo(context.Background(), "acme", "widget", "widget"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+}
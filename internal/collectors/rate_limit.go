@@ -0,0 +1,166 @@
+package collectors
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// secondaryRateLimitBackoff is how long to pause after a secondary rate limit
+// response that doesn't say when to retry, as recommended by GitHub
+const secondaryRateLimitBackoff = time.Minute
+
+// RateLimitError is returned when GitHub rejects, or would reject, a request
+// because a primary or secondary rate limit has been exceeded
+type RateLimitError struct {
+	StatusCode int
+	ResetAt    time.Time
+	Secondary  bool
+}
+
+func (e *RateLimitError) Error() string {
+	kind := "rate limit"
+	if e.Secondary {
+		kind = "secondary rate limit"
+	}
+
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("GitHub API %s exceeded, requests paused until %s", kind, e.ResetAt.Format(time.RFC3339))
+	}
+
+	return fmt.Sprintf("GitHub API %s exceeded (status %d), resets at %s", kind, e.StatusCode, e.ResetAt.Format(time.RFC3339))
+}
+
+// RetryAfter returns how long to wait before the limit resets
+func (e *RateLimitError) RetryAfter() time.Duration {
+	return time.Until(e.ResetAt)
+}
+
+// rateLimiter remembers until when GitHub API requests should be held back
+type rateLimiter struct {
+	mu          sync.Mutex
+	pausedUntil time.Time
+	secondary   bool
+}
+
+// pauseUntil holds requests back until t, never shortening an existing pause
+func (r *rateLimiter) pauseUntil(t time.Time, secondary bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t.After(r.pausedUntil) {
+		r.pausedUntil = t
+		r.secondary = secondary
+	}
+}
+
+// check returns a RateLimitError if requests are currently paused
+func (r *rateLimiter) check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Now().Before(r.pausedUntil) {
+		return &RateLimitError{ResetAt: r.pausedUntil, Secondary: r.secondary}
+	}
+
+	return nil
+}
+
+// observeRateLimit records the rate limit headers of a GitHub API response,
+// pausing further requests once the remaining budget is exhausted
+func (gc *GHCRCollector) observeRateLimit(resp *http.Response) {
+	limitHeader := resp.Header.Get("X-RateLimit-Limit")
+	remainingHeader := resp.Header.Get("X-RateLimit-Remaining")
+	resetHeader := resp.Header.Get("X-RateLimit-Reset")
+
+	if limitHeader == "" && remainingHeader == "" && resetHeader == "" {
+		return
+	}
+
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+
+	labels := prometheus.Labels{"resource": resource}
+
+	if limit, err := strconv.Atoi(limitHeader); err == nil {
+		gc.metrics.RateLimitLimitGauge.With(labels).Set(float64(limit))
+	}
+
+	reset, resetErr := strconv.ParseInt(resetHeader, 10, 64)
+	if resetErr == nil {
+		gc.metrics.RateLimitResetGauge.With(labels).Set(float64(reset))
+	}
+
+	remaining, err := strconv.Atoi(remainingHeader)
+	if err != nil {
+		return
+	}
+
+	gc.metrics.RateLimitRemainingGauge.With(labels).Set(float64(remaining))
+
+	if remaining == 0 && resetErr == nil {
+		resetAt := time.Unix(reset, 0)
+		if resetAt.After(time.Now()) {
+			slog.Warn("GitHub API rate limit exhausted, pausing requests",
+				"resource", resource,
+				"reset_at", resetAt.Format(time.RFC3339))
+
+			gc.rateLimit.pauseUntil(resetAt, false)
+		}
+	}
+}
+
+// rateLimitError inspects a failed GitHub API response and returns a
+// RateLimitError if it was rejected by a primary or secondary rate limit.
+// The response body is consumed but not closed.
+func (gc *GHCRCollector) rateLimitError(resp *http.Response) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	now := time.Now()
+
+	// Retry-After is only sent for secondary rate limits
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return gc.pauseForRateLimit(resp.StatusCode, now.Add(time.Duration(seconds)*time.Second), true)
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return gc.pauseForRateLimit(resp.StatusCode, time.Unix(reset, 0), false)
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err == nil && strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return gc.pauseForRateLimit(resp.StatusCode, now.Add(secondaryRateLimitBackoff), true)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return gc.pauseForRateLimit(resp.StatusCode, now.Add(secondaryRateLimitBackoff), true)
+	}
+
+	return nil
+}
+
+func (gc *GHCRCollector) pauseForRateLimit(statusCode int, resetAt time.Time, secondary bool) *RateLimitError {
+	slog.Warn("GitHub API rate limit hit, pausing requests",
+		"status_code", statusCode,
+		"secondary", secondary,
+		"reset_at", resetAt.Format(time.RFC3339))
+
+	gc.rateLimit.pauseUntil(resetAt, secondary)
+
+	return &RateLimitError{StatusCode: statusCode, ResetAt: resetAt, Secondary: secondary}
+}
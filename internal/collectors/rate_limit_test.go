@@ -0,0 +1,153 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRateLimitHeadersExportedAndExhaustionPauses(t *testing.T) {
+	var requests atomic.Int32
+
+	reset := time.Now().Add(30 * time.Minute).Unix()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "filesystem-exporter", "version_count": 3}`))
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	if _, err := collector.getPackageInfo(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter"); err != nil {
+		t.Fatalf("Expected first request to succeed, got: %v", err)
+	}
+
+	labels := prometheus.Labels{"resource": "core"}
+
+	if got := testutil.ToFloat64(collector.metrics.RateLimitLimitGauge.With(labels)); got != 5000 {
+		t.Errorf("Expected limit 5000, got %f", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.RateLimitRemainingGauge.With(labels)); got != 0 {
+		t.Errorf("Expected remaining 0, got %f", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.RateLimitResetGauge.With(labels)); got != float64(reset) {
+		t.Errorf("Expected reset %d, got %f", reset, got)
+	}
+
+	_, err := collector.getPackageInfo(context.Background(), "d0ugal", "filesystem-exporter", "filesystem-exporter")
+
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected RateLimitError while paused, got: %v", err)
+	}
+
+	if rateLimitErr.ResetAt.Unix() != reset {
+		t.Errorf("Expected reset at %d, got %d", reset, rateLimitErr.ResetAt.Unix())
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected no further requests while paused, got %d requests", requests.Load())
+	}
+}
+
+func TestSecondaryRateLimitDetection(t *testing.T) {
+	testCases := []struct {
+		description string
+		handler     http.HandlerFunc
+		minWait     time.Duration
+	}{
+		{
+			description: "Retry-After header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "120")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			minWait: 110 * time.Second,
+		},
+		{
+			description: "Secondary rate limit message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit. Please wait a few minutes before you try again."}`))
+			},
+			minWait: 50 * time.Second,
+		},
+		{
+			description: "Too many requests",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			minWait: 50 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			collector := newTestCollector(t, &config.Config{}, server)
+
+			_, err := collector.makeGitHubAPIRequest(context.Background(), "/users/d0ugal/packages/container/filesystem-exporter")
+
+			var rateLimitErr *RateLimitError
+			if !errors.As(err, &rateLimitErr) {
+				t.Fatalf("Expected RateLimitError, got: %v", err)
+			}
+
+			if !rateLimitErr.Secondary {
+				t.Error("Expected secondary rate limit to be detected")
+			}
+
+			if rateLimitErr.RetryAfter() < tc.minWait {
+				t.Errorf("Expected to wait at least %s, got %s", tc.minWait, rateLimitErr.RetryAfter())
+			}
+
+			if err := collector.rateLimit.check(); err == nil {
+				t.Error("Expected requests to be paused after a secondary rate limit")
+			}
+		})
+	}
+}
+
+func TestForbiddenWithoutRateLimitIsNotPaused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	collector := newTestCollector(t, &config.Config{}, server)
+
+	_, err := collector.makeGitHubAPIRequest(context.Background(), "/users/d0ugal/packages/container/filesystem-exporter")
+	if err == nil {
+		t.Fatal("Expected an error for a forbidden response")
+	}
+
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		t.Fatalf("Expected a plain error, got rate limit error: %v", err)
+	}
+
+	if err := collector.rateLimit.check(); err != nil {
+		t.Errorf("Expected requests not to be paused, got: %v", err)
+	}
+}
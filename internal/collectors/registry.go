@@ -0,0 +1,553 @@
+package collectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Media types of the manifests understood by the registry client
+const (
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// maxManifestSize matches the manifest size limit of common registries and
+// bounds the size of config blobs read into memory
+const maxManifestSize = 4 << 20
+
+// defaultRegistryTokenLifetime is used when a token response has no expiry
+const defaultRegistryTokenLifetime = time.Minute
+
+// ociDescriptor references content in the registry
+type ociDescriptor struct {
+	MediaType    string            `json:"mediaType"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	Platform     *ociPlatform      `json:"platform,omitempty"`
+}
+
+// ociPlatform is the platform an image in an index was built for
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// String formats the platform the way docker does, such as linux/arm64/v8
+func (p ociPlatform) String() string {
+	platform := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		platform += "/" + p.Variant
+	}
+
+	return platform
+}
+
+// ociManifest is an image manifest or, when Manifests is set, an image index
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Manifests     []ociDescriptor   `json:"manifests"`
+	Subject       *ociDescriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// isIndex reports whether the manifest is a multi-platform image index
+func (m *ociManifest) isIndex() bool {
+	return m.MediaType == mediaTypeOCIIndex || m.MediaType == mediaTypeDockerManifestList
+}
+
+// ociImageConfig is the subset of an image config blob the exporter uses
+type ociImageConfig struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+	Created      string `json:"created,omitempty"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// ociPlatformImage is the image for a single platform
+type ociPlatformImage struct {
+	platform string // Empty when the platform isn't known
+	digest   string
+	manifest *ociManifest
+	config   *ociImageConfig // Only set once the config blob has been fetched
+}
+
+// ociImage is the image a tag points to, with one entry per platform
+type ociImage struct {
+	digest       string
+	manifest     *ociManifest // The index for multi-platform images
+	platforms    []ociPlatformImage
+	attestations []*ociManifest // Attestation manifests stored in the index by buildx
+}
+
+// Standard OCI annotation keys, also used as image config labels
+const (
+	annotationCreated  = "org.opencontainers.image.created"
+	annotationRevision = "org.opencontainers.image.revision"
+	annotationSource   = "org.opencontainers.image.source"
+	annotationVersion  = "org.opencontainers.image.version"
+)
+
+// annotation looks a key up in the index annotations, then in each platform's
+// manifest annotations and finally in each platform's config labels
+func (image *ociImage) annotation(key string) string {
+	if value := image.manifest.Annotations[key]; value != "" {
+		return value
+	}
+
+	for _, platformImage := range image.platforms {
+		if value := platformImage.manifest.Annotations[key]; value != "" {
+			return value
+		}
+	}
+
+	for _, platformImage := range image.platforms {
+		if platformImage.config == nil {
+			continue
+		}
+
+		if value := platformImage.config.Config.Labels[key]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// created returns when the image was built, from its created annotation or
+// label, falling back to the created time in its config
+func (image *ociImage) created() time.Time {
+	if created := parseVersionTime(image.annotation(annotationCreated)); !created.IsZero() {
+		return created
+	}
+
+	for _, platformImage := range image.platforms {
+		if platformImage.config == nil {
+			continue
+		}
+
+		if created := parseVersionTime(platformImage.config.Created); !created.IsZero() {
+			return created
+		}
+	}
+
+	return time.Time{}
+}
+
+// registryToken is a bearer token for a single repository scope
+type registryToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// registryClient is a minimal OCI Distribution client for reading manifests
+// and blobs, authenticating with bearer tokens from the registry's token
+// endpoint. Tokens are requested anonymously, or with the GitHub token for
+// private images.
+type registryClient struct {
+	client   *http.Client
+	baseURL  string
+	password string
+
+	mu     sync.Mutex
+	tokens map[string]registryToken // scope -> token
+
+	// Set once the registry answers a referrers request with a 404, so it
+	// isn't asked again
+	noReferrersAPI atomic.Bool
+}
+
+func newRegistryClient(client *http.Client, baseURL, token string) *registryClient {
+	return &registryClient{
+		client:   client,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		password: token,
+		tokens:   make(map[string]registryToken),
+	}
+}
+
+// registryRepository returns the registry repository for a GitHub package,
+// which is always lower case
+func registryRepository(owner, packageName string) string {
+	return strings.ToLower(owner + "/" + packageName)
+}
+
+// getImage fetches the manifest a reference points to and, for image
+// indexes, the manifest of each platform
+func (rc *registryClient) getImage(ctx context.Context, repository, reference string) (*ociImage, error) {
+	manifest, digest, err := rc.getManifest(ctx, repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	image := &ociImage{digest: digest, manifest: manifest}
+
+	if !manifest.isIndex() {
+		platformImage := ociPlatformImage{digest: digest, manifest: manifest}
+
+		// Single platform images only record their platform in the config
+		if config := rc.getPlatformConfig(ctx, repository, manifest); config != nil {
+			platformImage.config = config
+			platformImage.platform = ociPlatform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}.String()
+		}
+
+		image.platforms = append(image.platforms, platformImage)
+
+		return image, nil
+	}
+
+	for _, descriptor := range manifest.Manifests {
+		if descriptor.Annotations[annotationDockerReferenceType] == dockerReferenceTypeAttestation {
+			attestation, _, err := rc.getManifest(ctx, repository, descriptor.Digest)
+			if err != nil {
+				slog.Warn("Failed to get attestation manifest", "repository", repository, "digest", descriptor.Digest, "error", err)
+				continue
+			}
+
+			image.attestations = append(image.attestations, attestation)
+
+			continue
+		}
+
+		// Skip other entries that aren't runnable images
+		if descriptor.Platform == nil || descriptor.Platform.OS == "unknown" {
+			continue
+		}
+
+		platformManifest, platformDigest, err := rc.getManifest(ctx, repository, descriptor.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s manifest: %w", descriptor.Platform, err)
+		}
+
+		image.platforms = append(image.platforms, ociPlatformImage{
+			platform: descriptor.Platform.String(),
+			digest:   platformDigest,
+			manifest: platformManifest,
+			config:   rc.getPlatformConfig(ctx, repository, platformManifest),
+		})
+	}
+
+	return image, nil
+}
+
+// getPlatformConfig fetches the config of a platform's image, returning nil
+// if it can't be read. The config only adds detail to an image, so failing to
+// read it isn't an error.
+func (rc *registryClient) getPlatformConfig(ctx context.Context, repository string, manifest *ociManifest) *ociImageConfig {
+	config, err := rc.getImageConfig(ctx, repository, manifest)
+	if err != nil {
+		slog.Warn("Failed to get image config", "repository", repository, "config", manifest.Config.Digest, "error", err)
+		return nil
+	}
+
+	return config
+}
+
+// getManifest fetches a manifest or index by tag or digest, returning it
+// along with its digest
+func (rc *registryClient) getManifest(ctx context.Context, repository, reference string) (*ociManifest, string, error) {
+	accept := strings.Join([]string{mediaTypeOCIIndex, mediaTypeOCIManifest, mediaTypeDockerManifestList, mediaTypeDockerManifest}, ", ")
+
+	resp, err := rc.get(ctx, repository, fmt.Sprintf("/v2/%s/manifests/%s", repository, reference), accept)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get manifest %s:%s: %w", repository, reference, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read manifest %s:%s: %w", repository, reference, err)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, "", fmt.Errorf("failed to decode manifest %s:%s: %w", repository, reference, err)
+	}
+
+	// Docker manifests may only give their media type in the header
+	if manifest.MediaType == "" {
+		manifest.MediaType, _, _ = strings.Cut(resp.Header.Get("Content-Type"), ";")
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+
+	return &manifest, digest, nil
+}
+
+// getReferrers lists the manifests that refer to a digest, such as signatures
+// and attestations, using the referrers API. Registries with the API answer
+// with an empty list when there are none, so a 404 means the registry
+// doesn't support it and it isn't asked again.
+func (rc *registryClient) getReferrers(ctx context.Context, repository, digest string) ([]ociDescriptor, error) {
+	if rc.noReferrersAPI.Load() {
+		return nil, nil
+	}
+
+	resp, err := rc.get(ctx, repository, fmt.Sprintf("/v2/%s/referrers/%s", repository, digest), mediaTypeOCIIndex)
+	if err != nil {
+		if isNotFound(err) {
+			if !rc.noReferrersAPI.Swap(true) {
+				slog.Info("Registry doesn't support the referrers API, only cosign tags will be checked", "registry", rc.baseURL)
+			}
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to get referrers of %s@%s: %w", repository, digest, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing response body", "error", err)
+		}
+	}()
+
+	var index ociManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("failed to decode referrers of %s@%s: %w", repository, digest, err)
+	}
+
+	return index.Manifests, nil
+}
+
+// isNotFound reports whether a registry request failed because the content
+// doesn't exist
+func isNotFound(err error) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// getImageConfig fetches and decodes the config blob of an image manifest
+func (rc *registryClient) getImageConfig(ctx context.Context, repository string, manifest *ociManifest) (*ociImageConfig, error) {
+	if manifest.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest has no config")
+	}
+
+	body, err := rc.getBlob(ctx, repository, manifest.Config.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ociImageConfig
+	if err := json.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", manifest.Config.Digest, err)
+	}
+
+	return &config, nil
+}
+
+// getBlob fetches a small blob, such as an image config, by digest
+func (rc *registryClient) getBlob(ctx context.Context, repository, digest string) ([]byte, error) {
+	resp, err := rc.get(ctx, repository, fmt.Sprintf("/v2/%s/blobs/%s", repository, digest), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", digest, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", digest, err)
+	}
+
+	return body, nil
+}
+
+// get makes an authenticated GET request, fetching a new bearer token and
+// retrying once when the registry challenges for one
+func (rc *registryClient) get(ctx context.Context, repository, path, accept string) (*http.Response, error) {
+	scope := "repository:" + repository + ":pull"
+
+	resp, err := rc.do(ctx, path, accept, rc.cachedToken(scope))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Error("Error closing response body", "error", closeErr)
+		}
+
+		token, err := rc.fetchToken(ctx, challenge, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = rc.do(ctx, path, accept, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Error("Error closing response body", "error", closeErr)
+		}
+
+		return nil, &APIError{StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+func (rc *registryClient) do(ctx context.Context, path, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rc.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (rc *registryClient) cachedToken(scope string) string {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	token, ok := rc.tokens[scope]
+	if !ok || time.Now().After(token.expiresAt) {
+		return ""
+	}
+
+	return token.value
+}
+
+// registryTokenResponse is the token endpoint response. Registries return
+// the token as token, access_token or both.
+type registryTokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+var challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// fetchToken requests a bearer token from the realm in a WWW-Authenticate
+// challenge. The GitHub token is only sent to realms on the registry's host.
+func (rc *registryClient) fetchToken(ctx context.Context, challenge, scope string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+
+	values := make(map[string]string)
+	for _, match := range challengeParamPattern.FindAllStringSubmatch(params, -1) {
+		values[strings.ToLower(match[1])] = match[2]
+	}
+
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid registry token realm %q", values["realm"])
+	}
+
+	query := realm.Query()
+	if service := values["service"]; service != "" {
+		query.Set("service", service)
+	}
+
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+
+	if base, err := url.Parse(rc.baseURL); err == nil && rc.password != "" && strings.EqualFold(realm.Host, base.Host) {
+		req.SetBasicAuth("ghcr-exporter", rc.password)
+	}
+
+	resp, err := rc.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request registry token: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			slog.Error("Error closing response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to request registry token: %w", &APIError{StatusCode: resp.StatusCode})
+	}
+
+	var tokenResponse registryTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+
+	token := tokenResponse.Token
+	if token == "" {
+		token = tokenResponse.AccessToken
+	}
+
+	if token == "" {
+		return "", fmt.Errorf("registry token response has no token")
+	}
+
+	lifetime := defaultRegistryTokenLifetime
+	if tokenResponse.ExpiresIn > 0 {
+		lifetime = time.Duration(tokenResponse.ExpiresIn) * time.Second
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Refresh a little early so a token doesn't expire mid-request
+	rc.tokens[scope] = registryToken{value: token, expiresAt: time.Now().Add(lifetime * 9 / 10)}
+
+	return token, nil
+}
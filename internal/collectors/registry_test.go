@@ -0,0 +1,348 @@
+package collectors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testRegistry is a stand-in OCI registry that requires a bearer token from
+// its own token endpoint, the way ghcr.io does
+type testRegistry struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu              sync.Mutex
+	manifests       map[string]testRegistryContent // repository@reference -> manifest
+	blobs           map[string][]byte              // repository@digest -> blob
+	referrers       map[string][]ociDescriptor     // repository@digest -> referrers, nil without the referrers API
+	referrersStatus int                            // Status the referrers API fails with, when set
+	manifestStatus  int                            // Status manifest requests fail with, when set
+
+	tokenRequests    atomic.Int32
+	manifestRequests atomic.Int32
+	referrerRequests atomic.Int32
+	password         atomic.Value // Basic auth password of the last token request
+}
+
+type testRegistryContent struct {
+	mediaType string
+	digest    string
+	body      []byte
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	registry := &testRegistry{
+		t:         t,
+		manifests: make(map[string]testRegistryContent),
+		blobs:     make(map[string][]byte),
+	}
+
+	registry.server = httptest.NewServer(http.HandlerFunc(registry.serveHTTP))
+	t.Cleanup(registry.server.Close)
+
+	return registry
+}
+
+func (r *testRegistry) client(token string) *registryClient {
+	return newRegistryClient(r.server.Client(), r.server.URL, token)
+}
+
+func testDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// addBlob stores a blob and returns its descriptor
+func (r *testRegistry) addBlob(repository, mediaType string, data []byte) ociDescriptor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	digest := testDigest(data)
+	r.blobs[repository+"@"+digest] = data
+
+	return ociDescriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data))}
+}
+
+// addManifest stores a manifest under its digest and any tags, returning its
+// descriptor
+func (r *testRegistry) addManifest(repository string, manifest ociManifest, tags ...string) ociDescriptor {
+	r.t.Helper()
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		r.t.Fatalf("Failed to encode manifest: %v", err)
+	}
+
+	content := testRegistryContent{mediaType: manifest.MediaType, digest: testDigest(body), body: body}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, reference := range append([]string{content.digest}, tags...) {
+		r.manifests[repository+"@"+reference] = content
+	}
+
+	return ociDescriptor{MediaType: manifest.MediaType, Digest: content.digest, Size: int64(len(body))}
+}
+
+// addImage stores a single platform image with layers of the given sizes
+func (r *testRegistry) addImage(repository string, platform ociPlatform, config ociImageConfig, layerSizes ...int) ociDescriptor {
+	r.t.Helper()
+
+	config.OS = platform.OS
+	config.Architecture = platform.Architecture
+	config.Variant = platform.Variant
+
+	configBody, err := json.Marshal(config)
+	if err != nil {
+		r.t.Fatalf("Failed to encode config: %v", err)
+	}
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeOCIManifest,
+		Config:        r.addBlob(repository, "application/vnd.oci.image.config.v1+json", configBody),
+	}
+
+	for i, size := range layerSizes {
+		// Unique content so every layer gets its own digest
+		layer := make([]byte, size)
+		copy(layer, fmt.Sprintf("%s %s %d", repository, platform, i))
+
+		manifest.Layers = append(manifest.Layers, r.addBlob(repository, "application/vnd.oci.image.layer.v1.tar+gzip", layer))
+	}
+
+	descriptor := r.addManifest(repository, manifest)
+	descriptor.Platform = &platform
+
+	return descriptor
+}
+
+// addReferrer lists a descriptor as referring to a digest, enabling the
+// referrers API
+func (r *testRegistry) addReferrer(repository, digest string, referrer ociDescriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.referrers == nil {
+		r.referrers = make(map[string][]ociDescriptor)
+	}
+
+	r.referrers[repository+"@"+digest] = append(r.referrers[repository+"@"+digest], referrer)
+}
+
+// tag points a tag at a stored manifest
+func (r *testRegistry) tag(repository, tag string, descriptor ociDescriptor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.manifests[repository+"@"+tag] = r.manifests[repository+"@"+descriptor.Digest]
+}
+
+// addIndex stores an image index of the given manifests under tags
+func (r *testRegistry) addIndex(repository string, manifests []ociDescriptor, tags ...string) ociDescriptor {
+	r.t.Helper()
+
+	return r.addManifest(repository, ociManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeOCIIndex,
+		Manifests:     manifests,
+	}, tags...)
+}
+
+func (r *testRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		r.tokenRequests.Add(1)
+
+		if _, password, ok := req.BasicAuth(); ok {
+			r.password.Store(password)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"token": "registry-token:%s", "expires_in": 300}`, req.URL.Query().Get("scope"))
+
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+
+	kind := "/blobs/"
+
+	for _, candidate := range []string{"/manifests/", "/referrers/"} {
+		if strings.Contains(path, candidate) {
+			kind = candidate
+		}
+	}
+
+	repository, reference, ok := strings.Cut(path, kind)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	scope := "repository:" + repository + ":pull"
+	if req.Header.Get("Authorization") != "Bearer registry-token:"+scope {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry",scope="%s"`, r.server.URL, scope))
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	r.mu.Lock()
+	manifest, isManifest := r.manifests[repository+"@"+reference]
+	blob, isBlob := r.blobs[repository+"@"+reference]
+	referrers, hasReferrersAPI := r.referrers[repository+"@"+reference], r.referrers != nil
+	referrersStatus := r.referrersStatus
+	manifestStatus := r.manifestStatus
+	r.mu.Unlock()
+
+	if kind == "/manifests/" && manifestStatus != 0 {
+		w.WriteHeader(manifestStatus)
+		return
+	}
+
+	if kind == "/referrers/" {
+		r.referrerRequests.Add(1)
+
+		if referrersStatus != 0 {
+			w.WriteHeader(referrersStatus)
+			return
+		}
+	}
+
+	switch {
+	case kind == "/manifests/" && isManifest:
+		r.manifestRequests.Add(1)
+
+		w.Header().Set("Content-Type", manifest.mediaType)
+		w.Header().Set("Docker-Content-Digest", manifest.digest)
+		_, _ = w.Write(manifest.body)
+	case kind == "/blobs/" && isBlob:
+		_, _ = w.Write(blob)
+	case kind == "/referrers/" && hasReferrersAPI:
+		w.Header().Set("Content-Type", mediaTypeOCIIndex)
+		_ = json.NewEncoder(w).Encode(ociManifest{
+			SchemaVersion: 2,
+			MediaType:     mediaTypeOCIIndex,
+			Manifests:     referrers,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRegistryClientGetImageIndex(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "MQTT-Exporter")
+
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 100, 200)
+	arm64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64", Variant: "v8"}, ociImageConfig{}, 150)
+	attestation := registry.addImage(repository, ociPlatform{OS: "unknown", Architecture: "unknown"}, ociImageConfig{}, 10)
+	index := registry.addIndex(repository, []ociDescriptor{amd64, arm64, attestation}, "v1.0.0")
+
+	client := registry.client("")
+
+	image, err := client.getImage(context.Background(), repository, "v1.0.0")
+	if err != nil {
+		t.Fatalf("Failed to get image: %v", err)
+	}
+
+	if image.digest != index.Digest || !image.manifest.isIndex() {
+		t.Errorf("Expected the index %s, got %s", index.Digest, image.digest)
+	}
+
+	if len(image.platforms) != 2 {
+		t.Fatalf("Expected 2 platforms without the attestation, got %d", len(image.platforms))
+	}
+
+	if image.platforms[1].platform != "linux/arm64/v8" || image.platforms[1].digest != arm64.Digest {
+		t.Errorf("Expected linux/arm64/v8 at %s, got %s at %s", arm64.Digest, image.platforms[1].platform, image.platforms[1].digest)
+	}
+
+	if len(image.platforms[0].manifest.Layers) != 2 {
+		t.Errorf("Expected the amd64 manifest to have 2 layers, got %d", len(image.platforms[0].manifest.Layers))
+	}
+
+	if image.platforms[0].config == nil || image.platforms[0].config.Architecture != "amd64" {
+		t.Errorf("Expected the amd64 config to be fetched, got %+v", image.platforms[0].config)
+	}
+
+	if got := registry.tokenRequests.Load(); got != 1 {
+		t.Errorf("Expected the bearer token to be reused, got %d token requests", got)
+	}
+}
+
+func TestRegistryClientGetImageSingleManifest(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	image := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{Created: "2026-10-01T12:00:00Z"}, 100)
+	registry.tag(repository, "latest", image)
+
+	got, err := registry.client("test-token").getImage(context.Background(), repository, "latest")
+	if err != nil {
+		t.Fatalf("Failed to get image: %v", err)
+	}
+
+	if len(got.platforms) != 1 || got.platforms[0].platform != "linux/amd64" {
+		t.Fatalf("Expected a single linux/amd64 image from the config, got %+v", got.platforms)
+	}
+
+	if got.platforms[0].config == nil || got.platforms[0].config.Created != "2026-10-01T12:00:00Z" {
+		t.Errorf("Expected the image config to be kept, got %+v", got.platforms[0].config)
+	}
+
+	if password, _ := registry.password.Load().(string); password != "test-token" {
+		t.Errorf("Expected the GitHub token to be sent to the token endpoint, got %q", password)
+	}
+}
+
+func TestRegistryClientNotFound(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	_, err := registry.client("").getImage(context.Background(), "d0ugal/mqtt-exporter", "missing")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 APIError, got: %v", err)
+	}
+}
+
+func TestRegistryClientDoesNotSendCredentialsToOtherHosts(t *testing.T) {
+	var authorized atomic.Bool
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		authorized.Store(ok)
+
+		_, _ = w.Write([]byte(`{"access_token": "anonymous"}`))
+	}))
+	defer tokenServer.Close()
+
+	client := newRegistryClient(tokenServer.Client(), "https://ghcr.io", "test-token")
+
+	token, err := client.fetchToken(context.Background(), fmt.Sprintf(`Bearer realm="%s/token",service="ghcr.io"`, tokenServer.URL), "repository:d0ugal/mqtt-exporter:pull")
+	if err != nil {
+		t.Fatalf("Failed to fetch token: %v", err)
+	}
+
+	if token != "anonymous" {
+		t.Errorf("Expected the access_token to be used, got %q", token)
+	}
+
+	if authorized.Load() {
+		t.Error("Expected no credentials to be sent to a realm on another host")
+	}
+}
@@ -0,0 +1,107 @@
+package collectors
+
+import (
+	"log/slog"
+	"strconv"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// release is a tag that parsed as a semantic version
+type release struct {
+	version semVersion
+	target  tagTarget
+}
+
+// releaseSummary is what the release metrics are built from
+type releaseSummary struct {
+	latestStable     *release
+	latestPrerelease *release
+	stablePerMajor   map[int]int
+}
+
+// summarizeReleases finds the highest stable and pre-release versions among
+// the tags, and counts stable releases per major version
+func summarizeReleases(tags map[string]tagTarget) releaseSummary {
+	summary := releaseSummary{stablePerMajor: make(map[int]int)}
+
+	// v1.2.3 and 1.2.3 are the same release
+	seen := make(map[string]bool)
+
+	for tag, target := range tags {
+		version, ok := parseSemver(tag)
+		if !ok || seen[version.String()] {
+			continue
+		}
+
+		seen[version.String()] = true
+		candidate := &release{version: version, target: target}
+
+		if version.isPrerelease() {
+			if summary.latestPrerelease == nil || version.compare(summary.latestPrerelease.version) > 0 {
+				summary.latestPrerelease = candidate
+			}
+
+			continue
+		}
+
+		summary.stablePerMajor[version.major]++
+
+		if summary.latestStable == nil || version.compare(summary.latestStable.version) > 0 {
+			summary.latestStable = candidate
+		}
+	}
+
+	return summary
+}
+
+// updateReleaseMetrics exports the latest stable release, when the newest
+// stable and pre-release versions were published, and stable releases per
+// major version
+func (gc *GHCRCollector) updateReleaseMetrics(pkg config.PackageGroup, tags map[string]tagTarget) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	summary := summarizeReleases(tags)
+
+	gc.metrics.PackageLatestReleaseInfoGauge.DeletePartialMatch(labels)
+	gc.metrics.PackageReleasesGauge.DeletePartialMatch(labels)
+
+	if summary.latestStable != nil {
+		gc.metrics.PackageLatestReleaseInfoGauge.With(prometheus.Labels{
+			"owner":   pkg.Owner,
+			"repo":    pkg.Repo,
+			"version": summary.latestStable.version.String(),
+		}).Set(1)
+	}
+
+	setReleaseTimestamp(gc.metrics.PackageLatestStableTimestampGauge, labels, summary.latestStable)
+	setReleaseTimestamp(gc.metrics.PackageLatestPrereleaseTimestampGauge, labels, summary.latestPrerelease)
+
+	for major, count := range summary.stablePerMajor {
+		gc.metrics.PackageReleasesGauge.With(prometheus.Labels{
+			"owner": pkg.Owner,
+			"repo":  pkg.Repo,
+			"major": strconv.Itoa(major),
+		}).Set(float64(count))
+	}
+
+	slog.Debug("Updated release metrics",
+		"owner", pkg.Owner,
+		"package", pkg.Repo,
+		"major_versions", len(summary.stablePerMajor))
+}
+
+// setReleaseTimestamp sets the gauge to when the release was published, or
+// removes it when there is no such release
+func setReleaseTimestamp(gauge *prometheus.GaugeVec, labels prometheus.Labels, latest *release) {
+	if latest == nil || latest.target.created.IsZero() {
+		gauge.Delete(labels)
+		return
+	}
+
+	gauge.With(labels).Set(float64(latest.target.created.Unix()))
+}
@@ -0,0 +1,69 @@
+package collectors
+
+import (
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpdateReleaseMetrics(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+
+	publish := func(id int, createdAt string, tags ...string) GHCRVersionResponse {
+		version := testVersion(id, createdAt, tags...)
+		version.CreatedAt = createdAt
+
+		return version
+	}
+
+	collector.updateReleaseMetrics(pkg, currentTags([]GHCRVersionResponse{
+		publish(1, "2025-09-01T12:00:00Z", "v1.9.0"),
+		publish(2, "2025-09-10T12:00:00Z", "v1.10.0", "1.10.0", "v1.10", "latest"),
+		publish(3, "2025-09-15T12:00:00Z", "v2.0.0"),
+		publish(4, "2025-10-01T12:00:00Z", "v2.1.0-rc.1"),
+		publish(5, "2025-10-02T12:00:00Z", "sha-abc123"),
+	}))
+
+	if got := testutil.ToFloat64(collector.metrics.PackageLatestReleaseInfoGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "version": "2.0.0",
+	})); got != 1 {
+		t.Errorf("Expected the latest release to be 2.0.0, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageLatestStableTimestampGauge.With(labels)); got != 1757937600 {
+		t.Errorf("Expected the latest stable timestamp to be 1757937600, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageLatestPrereleaseTimestampGauge.With(labels)); got != 1759320000 {
+		t.Errorf("Expected the latest pre-release timestamp to be 1759320000, got %v", got)
+	}
+
+	for major, expected := range map[string]float64{"1": 2, "2": 1} {
+		if got := testutil.ToFloat64(collector.metrics.PackageReleasesGauge.With(prometheus.Labels{
+			"owner": "d0ugal", "repo": "mqtt-exporter", "major": major,
+		})); got != expected {
+			t.Errorf("Expected %v releases for major version %s, got %v", expected, major, got)
+		}
+	}
+
+	// The pre-release is promoted and the old releases are cleaned up
+	collector.updateReleaseMetrics(pkg, currentTags([]GHCRVersionResponse{
+		publish(6, "2025-10-05T12:00:00Z", "v2.1.0"),
+	}))
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageLatestReleaseInfoGauge); got != 1 {
+		t.Errorf("Expected a single latest release series, got %d", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageLatestPrereleaseTimestampGauge); got != 0 {
+		t.Errorf("Expected no pre-release timestamp without pre-releases, got %d series", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageReleasesGauge); got != 1 {
+		t.Errorf("Expected releases for a single major version, got %d series", got)
+	}
+}
@@ -0,0 +1,129 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"ghcr-exporter/internal/config"
+)
+
+// APIError is returned when the GitHub API responds with an unexpected status
+type APIError struct {
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d", e.StatusCode)
+}
+
+// retryPolicy retries transient failures with jittered exponential backoff
+type retryPolicy struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+func newRetryPolicy(cfg *config.Config) retryPolicy {
+	retry := cfg.GetRetryConfig()
+
+	return retryPolicy{
+		attempts:  retry.Attempts,
+		baseDelay: retry.BaseDelay.Duration,
+		maxDelay:  retry.MaxDelay.Duration,
+	}
+}
+
+// do runs operation until it succeeds, fails with a permanent error, the
+// attempts are used up or ctx is done
+func (p retryPolicy) do(ctx context.Context, operation func() error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = operation(); err == nil {
+			return nil
+		}
+
+		if attempt >= p.attempts {
+			break
+		}
+
+		if ctx.Err() != nil || !isRetryable(err) {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			retryAfter := rateLimitErr.RetryAfter()
+			if retryAfter > p.maxDelay {
+				// Waiting this long would stall the cycle; the rate limiter
+				// holds back later cycles until the limit resets instead
+				return err
+			}
+
+			delay = max(delay, retryAfter)
+		}
+
+		slog.Warn("Operation failed, retrying", "attempt", attempt, "error", err, "delay", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+
+	return fmt.Errorf("operation failed after %d attempts: %w", p.attempts, err)
+}
+
+// backoff returns the delay before the given retry, doubling from the base
+// delay up to the maximum with the upper half randomised
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.baseDelay
+	for i := 1; i < attempt && delay < p.maxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, p.maxDelay)
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + rand.N(half+1) //nolint:gosec // Jitter doesn't need a cryptographic source
+}
+
+// isRetryable reports whether err is a transient failure worth retrying:
+// server errors, timeouts and rate limits
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
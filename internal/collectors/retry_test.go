@@ -0,0 +1,171 @@
+package collectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() retryPolicy {
+	return retryPolicy{attempts: 3, baseDelay: time.Millisecond, maxDelay: 50 * time.Millisecond}
+}
+
+func TestRetryPolicyRetriesTransientErrors(t *testing.T) {
+	calls := 0
+
+	err := testRetryPolicy().do(context.Background(), func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("failed to get package info: %w", &APIError{StatusCode: http.StatusBadGateway})
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success on the third attempt, got: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryPolicyGivesUpAfterAttempts(t *testing.T) {
+	calls := 0
+
+	err := testRetryPolicy().do(context.Background(), func() error {
+		calls++
+		return &APIError{StatusCode: http.StatusServiceUnavailable}
+	})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected the last APIError to be wrapped, got: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryPermanentErrors(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusNotFound} {
+		calls := 0
+
+		_ = testRetryPolicy().do(context.Background(), func() error {
+			calls++
+			return &APIError{StatusCode: status}
+		})
+
+		if calls != 1 {
+			t.Errorf("Expected status %d not to be retried, got %d attempts", status, calls)
+		}
+	}
+}
+
+func TestRetryPolicyStopsWhenContextDone(t *testing.T) {
+	policy := retryPolicy{attempts: 5, baseDelay: time.Second, maxDelay: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	start := time.Now()
+
+	_ = policy.do(ctx, func() error {
+		calls++
+		return &APIError{StatusCode: http.StatusInternalServerError}
+	})
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected retry to stop when the context is done, took %s", elapsed)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected a single attempt before cancellation, got %d", calls)
+	}
+}
+
+func TestRetryPolicyHonoursRetryAfter(t *testing.T) {
+	calls := 0
+	start := time.Now()
+
+	err := testRetryPolicy().do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{StatusCode: http.StatusForbidden, ResetAt: time.Now().Add(30 * time.Millisecond), Secondary: true}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success after waiting, got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Expected to wait for Retry-After, only waited %s", elapsed)
+	}
+}
+
+func TestRetryPolicyDoesNotWaitBeyondMaxDelay(t *testing.T) {
+	calls := 0
+
+	_ = testRetryPolicy().do(context.Background(), func() error {
+		calls++
+		return &RateLimitError{StatusCode: http.StatusForbidden, ResetAt: time.Now().Add(time.Hour)}
+	})
+
+	if calls != 1 {
+		t.Errorf("Expected no retry when the rate limit resets after the max delay, got %d attempts", calls)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{attempts: 10, baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	testCases := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{attempt: 1, ceiling: 100 * time.Millisecond},
+		{attempt: 2, ceiling: 200 * time.Millisecond},
+		{attempt: 3, ceiling: 400 * time.Millisecond},
+		{attempt: 8, ceiling: time.Second},
+	}
+
+	for _, tc := range testCases {
+		for range 20 {
+			delay := policy.backoff(tc.attempt)
+			if delay < tc.ceiling/2 || delay > tc.ceiling {
+				t.Fatalf("Attempt %d: expected delay between %s and %s, got %s", tc.attempt, tc.ceiling/2, tc.ceiling, delay)
+			}
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		expected    bool
+	}{
+		{description: "Server error", err: &APIError{StatusCode: http.StatusInternalServerError}, expected: true},
+		{description: "Not found", err: &APIError{StatusCode: http.StatusNotFound}, expected: false},
+		{description: "Unauthorized", err: &APIError{StatusCode: http.StatusUnauthorized}, expected: false},
+		{description: "Rate limited", err: &RateLimitError{StatusCode: http.StatusForbidden}, expected: true},
+		{description: "Deadline exceeded", err: context.DeadlineExceeded, expected: true},
+		{description: "Cancelled", err: context.Canceled, expected: false},
+		{description: "Decode error", err: errors.New("invalid character"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := isRetryable(tc.err); got != tc.expected {
+				t.Errorf("Expected isRetryable to be %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package collectors
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+)
+
+// semVersion is a tag parsed as a semantic version, with an optional v prefix
+type semVersion struct {
+	major, minor, patch int
+	prerelease          []string
+}
+
+// parseSemver parses tags such as v1.2.3, 1.2.3-rc.1 or 1.2.3+build.5.
+// Partial versions such as v1 or 1.2 are rejected, as they are usually
+// floating tags pointing at the same version as a full release tag.
+func parseSemver(tag string) (semVersion, bool) {
+	version := strings.TrimPrefix(tag, "v")
+
+	// Build metadata doesn't affect precedence
+	version, _, _ = strings.Cut(version, "+")
+
+	core, prerelease, hasPrerelease := strings.Cut(version, "-")
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return semVersion{}, false
+	}
+
+	numbers := make([]int, len(parts))
+
+	for i, part := range parts {
+		number, ok := parseSemverNumber(part)
+		if !ok {
+			return semVersion{}, false
+		}
+
+		numbers[i] = number
+	}
+
+	parsed := semVersion{major: numbers[0], minor: numbers[1], patch: numbers[2]}
+
+	if hasPrerelease {
+		parsed.prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range parsed.prerelease {
+			if identifier == "" {
+				return semVersion{}, false
+			}
+		}
+	}
+
+	return parsed, true
+}
+
+// parseSemverNumber parses a numeric identifier, which may not have leading zeroes
+func parseSemverNumber(value string) (int, bool) {
+	if value == "" || (len(value) > 1 && value[0] == '0') {
+		return 0, false
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+
+	return number, true
+}
+
+// isPrerelease reports whether the version has pre-release identifiers
+func (v semVersion) isPrerelease() bool {
+	return len(v.prerelease) > 0
+}
+
+// String formats the version without a v prefix or build metadata
+func (v semVersion) String() string {
+	version := strconv.Itoa(v.major) + "." + strconv.Itoa(v.minor) + "." + strconv.Itoa(v.patch)
+	if v.isPrerelease() {
+		version += "-" + strings.Join(v.prerelease, ".")
+	}
+
+	return version
+}
+
+// compare returns -1, 0 or 1 depending on whether v has lower, equal or
+// higher precedence than other
+func (v semVersion) compare(other semVersion) int {
+	for _, pair := range [][2]int{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			return cmp.Compare(pair[0], pair[1])
+		}
+	}
+
+	// A release has higher precedence than its pre-releases
+	switch {
+	case !v.isPrerelease() && !other.isPrerelease():
+		return 0
+	case !v.isPrerelease():
+		return 1
+	case !other.isPrerelease():
+		return -1
+	}
+
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if result := comparePrereleaseIdentifiers(v.prerelease[i], other.prerelease[i]); result != 0 {
+			return result
+		}
+	}
+
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
+
+// comparePrereleaseIdentifiers compares numeric identifiers numerically and
+// others lexically, with numeric identifiers sorting first
+func comparePrereleaseIdentifiers(a, b string) int {
+	aNumber, aErr := strconv.Atoi(a)
+	bNumber, bErr := strconv.Atoi(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(aNumber, bNumber)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
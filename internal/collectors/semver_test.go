@@ -0,0 +1,66 @@
+package collectors
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	testCases := []struct {
+		tag      string
+		expected string
+		valid    bool
+	}{
+		{tag: "v1.2.3", expected: "1.2.3", valid: true},
+		{tag: "1.2.3", expected: "1.2.3", valid: true},
+		{tag: "v2.0.0-rc.1", expected: "2.0.0-rc.1", valid: true},
+		{tag: "v1.2.3+build.5", expected: "1.2.3", valid: true},
+		{tag: "v1.2", valid: false},
+		{tag: "v1", valid: false},
+		{tag: "latest", valid: false},
+		{tag: "v01.2.3", valid: false},
+		{tag: "v1.2.3-", valid: false},
+		{tag: "sha-1.2.3", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			version, ok := parseSemver(tc.tag)
+			if ok != tc.valid {
+				t.Fatalf("Expected valid to be %t, got %t", tc.valid, ok)
+			}
+
+			if ok && version.String() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, version.String())
+			}
+		})
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// In ascending order of precedence, as in the semver specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.10.0",
+		"2.0.0",
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		lower, _ := parseSemver(ordered[i-1])
+		higher, _ := parseSemver(ordered[i])
+
+		if lower.compare(higher) != -1 || higher.compare(lower) != 1 {
+			t.Errorf("Expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	same, _ := parseSemver("v1.0.0+build.1")
+	if other, _ := parseSemver("1.0.0"); same.compare(other) != 0 {
+		t.Error("Expected build metadata to be ignored")
+	}
+}
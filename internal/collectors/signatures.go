@@ -0,0 +1,171 @@
+package collectors
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Annotations buildx uses to mark the attestation manifests in an index
+const (
+	annotationDockerReferenceType  = "vnd.docker.reference.type"
+	dockerReferenceTypeAttestation = "attestation-manifest"
+)
+
+// Annotations recording the in-toto predicate type of an attestation, as set
+// by cosign, buildx and sigstore bundles respectively
+var predicateTypeAnnotations = []string{
+	"predicateType",
+	"in-toto.io/predicate-type",
+	"dev.sigstore.bundle.predicateType",
+}
+
+// Artifact types of referrers that are signatures of their subject
+var signatureArtifactTypes = []string{
+	"application/vnd.dev.cosign.artifact.sig.v1+json",
+	"application/vnd.cncf.notary.signature",
+}
+
+// Artifact types of referrers that are SBOMs of their subject
+var sbomArtifactTypes = []string{
+	"application/spdx+json",
+	"application/vnd.cyclonedx+json",
+}
+
+// supplyChain records which signatures and attestations an image has
+type supplyChain struct {
+	signed     bool
+	sbom       bool
+	provenance bool
+}
+
+// addPredicateType records an attestation with the given in-toto predicate
+// type, such as https://slsa.dev/provenance/v1 or https://spdx.dev/Document
+func (s *supplyChain) addPredicateType(predicateType string) {
+	switch {
+	case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/"):
+		s.provenance = true
+	case strings.HasPrefix(predicateType, "https://spdx.dev/Document"),
+		strings.HasPrefix(predicateType, "https://cyclonedx.org/bom"):
+		s.sbom = true
+	}
+}
+
+// addAnnotations records the predicate type found in a manifest's or layer's
+// annotations
+func (s *supplyChain) addAnnotations(annotations map[string]string) {
+	for _, key := range predicateTypeAnnotations {
+		if predicateType := annotations[key]; predicateType != "" {
+			s.addPredicateType(predicateType)
+		}
+	}
+}
+
+// addReferrer records a referrer from its artifact type and annotations
+func (s *supplyChain) addReferrer(descriptor ociDescriptor) {
+	switch {
+	case slices.Contains(signatureArtifactTypes, descriptor.ArtifactType):
+		s.signed = true
+	case slices.Contains(sbomArtifactTypes, descriptor.ArtifactType):
+		s.sbom = true
+	}
+
+	s.addAnnotations(descriptor.Annotations)
+}
+
+// addAttestation records the predicate types of each layer of an attestation
+// manifest
+func (s *supplyChain) addAttestation(manifest *ociManifest) {
+	s.addAnnotations(manifest.Annotations)
+
+	for _, layer := range manifest.Layers {
+		s.addAnnotations(layer.Annotations)
+	}
+}
+
+// cosignTag returns the tag cosign stores an artifact of a digest under, such
+// as sha256-<hex>.sig for signatures
+func cosignTag(digest, suffix string) string {
+	return strings.Replace(digest, ":", "-", 1) + "." + suffix
+}
+
+// getSupplyChain finds the signatures and attestations of an image from the
+// attestations in its index, cosign's .sig and .att tags and the referrers API
+func (rc *registryClient) getSupplyChain(ctx context.Context, repository string, image *ociImage) (supplyChain, error) {
+	var chain supplyChain
+
+	for _, attestation := range image.attestations {
+		chain.addAttestation(attestation)
+	}
+
+	if _, _, err := rc.getManifest(ctx, repository, cosignTag(image.digest, "sig")); err == nil {
+		chain.signed = true
+	} else if !isNotFound(err) {
+		return chain, err
+	}
+
+	if attestation, _, err := rc.getManifest(ctx, repository, cosignTag(image.digest, "att")); err == nil {
+		chain.addAttestation(attestation)
+	} else if !isNotFound(err) {
+		return chain, err
+	}
+
+	// Referrers only add to what the tags found, so a failure isn't fatal
+	referrers, err := rc.getReferrers(ctx, repository, image.digest)
+	if err != nil {
+		slog.Warn("Failed to get image referrers", "repository", repository, "digest", image.digest, "error", err)
+	}
+
+	for _, referrer := range referrers {
+		chain.addReferrer(referrer)
+	}
+
+	return chain, nil
+}
+
+// boolToFloat converts a presence flag to a gauge value
+func boolToFloat(present bool) float64 {
+	if present {
+		return 1
+	}
+
+	return 0
+}
+
+// updateSupplyChainMetrics exports whether each tagged image is signed and
+// has SBOM and provenance attestations. Signatures can be added after an
+// image is pushed, so they're looked up every cycle, once per digest. A tag
+// whose lookup fails keeps its last known values.
+func (gc *GHCRCollector) updateSupplyChainMetrics(ctx context.Context, pkg config.PackageGroup, images map[string]*ociImage) {
+	repository := registryRepository(pkg.Owner, pkg.Repo)
+	chains := make(map[string]supplyChain)
+
+	for tag, image := range images {
+		chain, ok := chains[image.digest]
+		if !ok {
+			var err error
+
+			chain, err = gc.registry.getSupplyChain(ctx, repository, image)
+			if err != nil {
+				slog.Warn("Failed to get image signatures and attestations", "owner", pkg.Owner, "package", pkg.Repo, "tag", tag, "error", err)
+				continue
+			}
+
+			chains[image.digest] = chain
+		}
+
+		labels := prometheus.Labels{
+			"owner": pkg.Owner,
+			"repo":  pkg.Repo,
+			"tag":   tag,
+		}
+
+		gc.metrics.ImageSignedGauge.With(labels).Set(boolToFloat(chain.signed))
+		gc.metrics.ImageSBOMPresentGauge.With(labels).Set(boolToFloat(chain.sbom))
+		gc.metrics.ImageProvenancePresentGauge.With(labels).Set(boolToFloat(chain.provenance))
+	}
+}
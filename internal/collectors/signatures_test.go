@@ -0,0 +1,236 @@
+package collectors
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// addAttestation stores a manifest with a layer for each predicate type,
+// annotated with the given key
+func (r *testRegistry) addAttestation(repository, annotation string, predicateTypes ...string) ociManifest {
+	r.t.Helper()
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeOCIManifest,
+		Config:        r.addBlob(repository, "application/vnd.oci.image.config.v1+json", []byte("{}")),
+	}
+
+	for _, predicateType := range predicateTypes {
+		layer := r.addBlob(repository, "application/vnd.in-toto+json", []byte(predicateType))
+		layer.Annotations = map[string]string{annotation: predicateType}
+		manifest.Layers = append(manifest.Layers, layer)
+	}
+
+	return manifest
+}
+
+func TestCosignTag(t *testing.T) {
+	if got := cosignTag("sha256:abc123", "sig"); got != "sha256-abc123.sig" {
+		t.Errorf("Expected sha256-abc123.sig, got %s", got)
+	}
+}
+
+func TestUpdateSupplyChainMetrics(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	// Signed with cosign, with buildx SBOM and provenance attestations in the index
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 100)
+	buildxAttestation := registry.addManifest(repository, registry.addAttestation(repository, "in-toto.io/predicate-type",
+		"https://spdx.dev/Document", "https://slsa.dev/provenance/v0.2"))
+	buildxAttestation.Platform = &ociPlatform{OS: "unknown", Architecture: "unknown"}
+	buildxAttestation.Annotations = map[string]string{
+		annotationDockerReferenceType: dockerReferenceTypeAttestation,
+		"vnd.docker.reference.digest": amd64.Digest,
+	}
+	buildx := registry.addIndex(repository, []ociDescriptor{amd64, buildxAttestation})
+	signature := registry.addManifest(repository, ociManifest{SchemaVersion: 2, MediaType: mediaTypeOCIManifest})
+	registry.tag(repository, cosignTag(buildx.Digest, "sig"), signature)
+
+	// Unsigned, with a cosign provenance attestation
+	cosign := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 200)
+	registry.addManifest(repository, registry.addAttestation(repository, "predicateType", "https://slsa.dev/provenance/v1"),
+		cosignTag(cosign.Digest, "att"))
+
+	// Signed with a referrer, with an SBOM referrer
+	referred := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 300)
+	registry.addReferrer(repository, referred.Digest, ociDescriptor{ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"})
+	registry.addReferrer(repository, referred.Digest, ociDescriptor{ArtifactType: "application/spdx+json"})
+
+	bare := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 400)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, buildx, "buildx"),
+		imageVersion(2, cosign, "cosign"),
+		imageVersion(3, referred, "referrers"),
+		imageVersion(4, bare, "bare"),
+	}))
+
+	tests := []struct {
+		tag        string
+		signed     float64
+		sbom       float64
+		provenance float64
+	}{
+		{tag: "buildx", signed: 1, sbom: 1, provenance: 1},
+		{tag: "cosign", signed: 0, sbom: 0, provenance: 1},
+		{tag: "referrers", signed: 1, sbom: 1, provenance: 0},
+		{tag: "bare", signed: 0, sbom: 0, provenance: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter", "tag": tt.tag}
+
+			if got := testutil.ToFloat64(collector.metrics.ImageSignedGauge.With(labels)); got != tt.signed {
+				t.Errorf("Expected signed %v, got %v", tt.signed, got)
+			}
+
+			if got := testutil.ToFloat64(collector.metrics.ImageSBOMPresentGauge.With(labels)); got != tt.sbom {
+				t.Errorf("Expected SBOM present %v, got %v", tt.sbom, got)
+			}
+
+			if got := testutil.ToFloat64(collector.metrics.ImageProvenancePresentGauge.With(labels)); got != tt.provenance {
+				t.Errorf("Expected provenance present %v, got %v", tt.provenance, got)
+			}
+		})
+	}
+
+	// The buildx attestation isn't mistaken for a platform
+	if got := testutil.CollectAndCount(collector.metrics.ImagePlatformInfoGauge); got != 4 {
+		t.Errorf("Expected 4 platform info series, got %d", got)
+	}
+}
+
+func TestUpdateSupplyChainMetricsWithoutReferrers(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	signed := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 100)
+	signature := registry.addManifest(repository, ociManifest{SchemaVersion: 2, MediaType: mediaTypeOCIManifest})
+	registry.tag(repository, cosignTag(signed.Digest, "sig"), signature)
+
+	unsigned := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 200)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	tags := currentTags([]GHCRVersionResponse{
+		imageVersion(1, signed, "v1.0.0"),
+		imageVersion(2, unsigned, "v1.1.0"),
+	})
+
+	// A failing referrers API doesn't hide what the cosign tags found
+	registry.mu.Lock()
+	registry.referrersStatus = http.StatusInternalServerError
+	registry.mu.Unlock()
+
+	collector.updateImageMetrics(context.Background(), pkg, tags)
+
+	if got := testutil.ToFloat64(collector.metrics.ImageSignedGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0",
+	})); got != 1 {
+		t.Errorf("Expected v1.0.0 to be signed despite the referrers failure, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImageSignedGauge); got != 2 {
+		t.Errorf("Expected a signed series for both tags, got %d", got)
+	}
+
+	// A registry without the referrers API is only asked once
+	registry.mu.Lock()
+	registry.referrersStatus = http.StatusNotFound
+	registry.mu.Unlock()
+
+	requests := registry.referrerRequests.Load()
+
+	collector.updateImageMetrics(context.Background(), pkg, tags)
+	collector.updateImageMetrics(context.Background(), pkg, tags)
+
+	if got := registry.referrerRequests.Load() - requests; got != 1 {
+		t.Errorf("Expected a single referrers request once the API was found missing, got %d", got)
+	}
+}
+
+func TestUpdateImageMetricsKeepsSeriesOnFailure(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	signed := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 100)
+	signature := registry.addManifest(repository, ociManifest{SchemaVersion: 2, MediaType: mediaTypeOCIManifest})
+	registry.tag(repository, cosignTag(signed.Digest, "sig"), signature)
+
+	other := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 200)
+	moved := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 300)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	v1 := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0"}
+	v1Size := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0", "platform": "linux/amd64"}
+
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, signed, "v1.0.0"),
+		imageVersion(2, other, "v0.9.0"),
+	}))
+
+	size := testutil.ToFloat64(collector.metrics.ImageSizeGauge.With(v1Size))
+
+	registry.mu.Lock()
+	registry.manifestStatus = http.StatusInternalServerError
+	registry.mu.Unlock()
+
+	// The images are cached, but the .sig lookups fail
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(1, signed, "v1.0.0"),
+		imageVersion(2, other, "v0.9.0"),
+	}))
+
+	if got := testutil.ToFloat64(collector.metrics.ImageSignedGauge.With(v1)); got != 1 {
+		t.Errorf("Expected v1.0.0 to stay signed when its signature lookup fails, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.ImageSignedGauge); got != 2 {
+		t.Errorf("Expected both signed series to be kept, got %d", got)
+	}
+
+	// v1.0.0 moves to an image that can't be fetched
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(3, moved, "v1.0.0"),
+		imageVersion(2, other, "v0.9.0"),
+	}))
+
+	if got := testutil.ToFloat64(collector.metrics.ImageSizeGauge.With(v1Size)); got != size {
+		t.Errorf("Expected v1.0.0 to keep its last known size %v, got %v", size, got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.ImageSignedGauge.With(v1)); got != 1 {
+		t.Errorf("Expected v1.0.0 to keep its last known signature, got %v", got)
+	}
+
+	registry.mu.Lock()
+	registry.manifestStatus = 0
+	registry.mu.Unlock()
+
+	// Once the registry recovers, series of removed tags are deleted
+	collector.updateImageMetrics(context.Background(), pkg, currentTags([]GHCRVersionResponse{
+		imageVersion(3, moved, "v1.0.0"),
+	}))
+
+	if got := testutil.ToFloat64(collector.metrics.ImageSignedGauge.With(v1)); got != 0 {
+		t.Errorf("Expected v1.0.0's new image to be unsigned, got %v", got)
+	}
+
+	for _, vec := range []*prometheus.GaugeVec{collector.metrics.ImageSizeGauge, collector.metrics.ImageSignedGauge} {
+		if got := testutil.CollectAndCount(vec); got != 1 {
+			t.Errorf("Expected only v1.0.0's series to remain, got %d", got)
+		}
+	}
+}
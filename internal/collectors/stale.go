@@ -0,0 +1,51 @@
+package collectors
+
+import (
+	"sync"
+	"time"
+)
+
+// packageTracker remembers when each package group last saw each of its
+// packages, so metrics for packages that disappear can be removed
+type packageTracker struct {
+	mu       sync.Mutex
+	lastSeen map[string]map[string]time.Time // group name -> package name -> last seen
+}
+
+func newPackageTracker() *packageTracker {
+	return &packageTracker{
+		lastSeen: make(map[string]map[string]time.Time),
+	}
+}
+
+// markSeen records that the group saw the package at the given time
+func (t *packageTracker) markSeen(group, packageName string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	packages, ok := t.lastSeen[group]
+	if !ok {
+		packages = make(map[string]time.Time)
+		t.lastSeen[group] = packages
+	}
+
+	packages[packageName] = now
+}
+
+// expire forgets and returns the group's packages that haven't been seen for
+// longer than the grace period
+func (t *packageTracker) expire(group string, now time.Time, grace time.Duration) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var expired []string
+
+	for packageName, seen := range t.lastSeen[group] {
+		if now.Sub(seen) > grace {
+			expired = append(expired, packageName)
+			delete(t.lastSeen[group], packageName)
+		}
+	}
+
+	return expired
+}
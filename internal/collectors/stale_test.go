@@ -0,0 +1,132 @@
+package collectors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	promexporter_config "github.com/d0ugal/promexporter/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPackageTrackerExpire(t *testing.T) {
+	tracker := newPackageTracker()
+	now := time.Now()
+
+	tracker.markSeen("d0ugal-all", "filesystem-exporter", now)
+	tracker.markSeen("d0ugal-all", "mqtt-exporter", now.Add(-2*time.Hour))
+	tracker.markSeen("home-assistant-all", "home-assistant", now.Add(-2*time.Hour))
+
+	expired := tracker.expire("d0ugal-all", now, time.Hour)
+	if len(expired) != 1 || expired[0] != "mqtt-exporter" {
+		t.Fatalf("Expected only mqtt-exporter to expire, got %v", expired)
+	}
+
+	if expired := tracker.expire("d0ugal-all", now, time.Hour); len(expired) != 0 {
+		t.Errorf("Expected expired packages to be forgotten, got %v", expired)
+	}
+}
+
+func TestCollectOwnerPackagesRemovesStaleSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/users/d0ugal/packages":
+			_, _ = w.Write([]byte(`[{"name": "filesystem-exporter"}]`))
+		case "/users/d0ugal/packages/container/filesystem-exporter":
+			_, _ = w.Write([]byte(`{"name": "filesystem-exporter", "version_count": 5}`))
+		case "/users/d0ugal/packages/container/filesystem-exporter/versions":
+			_, _ = w.Write([]byte(`[{"id": 1, "created_at": "2025-10-01T12:00:00Z"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	group := config.PackageGroup{Owner: "d0ugal"}
+	cfg := &config.Config{
+		GitHub:   config.GitHubConfig{Token: promexporter_config.NewSensitiveString("test-token")},
+		Packages: []config.PackageGroup{group},
+	}
+	collector := newTestCollector(t, cfg, server)
+
+	// Series left behind by packages from earlier cycles
+	for repo, lastSeen := range map[string]time.Time{
+		"deleted-exporter": time.Now().Add(-2 * time.Hour),
+		"recent-exporter":  time.Now().Add(-time.Minute),
+	} {
+		labels := prometheus.Labels{"owner": "d0ugal", "repo": repo}
+		collector.metrics.PackageDownloadsGauge.With(labels).Set(3)
+		collector.metrics.PackageDownloadStatsGauge.With(labels).Set(100)
+		collector.metrics.PackageLastPublishedGauge.With(labels).Set(1700000000)
+		collector.packages.markSeen(group.GetName(), repo, lastSeen)
+	}
+
+	collector.collectOwnerPackages(context.Background(), group.GetName(), group)
+
+	for _, vec := range []*prometheus.GaugeVec{
+		collector.metrics.PackageDownloadsGauge,
+		collector.metrics.PackageDownloadStatsGauge,
+		collector.metrics.PackageLastPublishedGauge,
+	} {
+		// filesystem-exporter was collected and recent-exporter is within the grace period
+		if got := testutil.CollectAndCount(vec); got != 2 {
+			t.Errorf("Expected 2 series after removing the stale package, got %d", got)
+		}
+
+		if vec.Delete(prometheus.Labels{"owner": "d0ugal", "repo": "deleted-exporter"}) {
+			t.Error("Expected deleted-exporter series to have been removed")
+		}
+	}
+}
+
+func TestCollectOwnerPackagesZeroGracePeriod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/users/d0ugal/packages":
+			_, _ = w.Write([]byte(`[{"name": "filesystem-exporter"}]`))
+		case "/users/d0ugal/packages/container/filesystem-exporter":
+			_, _ = w.Write([]byte(`{"name": "filesystem-exporter", "version_count": 5}`))
+		case "/users/d0ugal/packages/container/filesystem-exporter/versions":
+			_, _ = w.Write([]byte(`[{"id": 1, "created_at": "2025-10-01T12:00:00Z"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	group := config.PackageGroup{Owner: "d0ugal"}
+	cfg := &config.Config{
+		GitHub:    config.GitHubConfig{Token: promexporter_config.NewSensitiveString("test-token")},
+		Collector: config.CollectorConfig{StaleGracePeriod: &config.Duration{}},
+		Packages:  []config.PackageGroup{group},
+	}
+	collector := newTestCollector(t, cfg, server)
+
+	// Seen a moment ago, but missing from this discovery
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "recent-exporter"}
+	collector.metrics.PackageDownloadsGauge.With(labels).Set(3)
+	collector.packages.markSeen(group.GetName(), "recent-exporter", time.Now().Add(-time.Second))
+
+	collector.collectOwnerPackages(context.Background(), group.GetName(), group)
+
+	// Only the package that is still present keeps its series
+	if got := testutil.CollectAndCount(collector.metrics.PackageDownloadsGauge); got != 1 {
+		t.Errorf("Expected only filesystem-exporter's series to remain, got %d", got)
+	}
+
+	if collector.metrics.PackageDownloadsGauge.Delete(labels) {
+		t.Error("Expected recent-exporter's series to be removed immediately")
+	}
+
+	if !collector.metrics.PackageDownloadsGauge.Delete(prometheus.Labels{"owner": "d0ugal", "repo": "filesystem-exporter"}) {
+		t.Error("Expected filesystem-exporter's series to be kept")
+	}
+}
@@ -0,0 +1,285 @@
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"ghcr-exporter/internal/config"
+	"github.com/d0ugal/promexporter/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// blobSizes maps the digests of the blobs a version stores to their sizes
+type blobSizes map[string]int64
+
+// add records the blobs of a single image manifest
+func (b blobSizes) add(manifest *ociManifest) {
+	if manifest.Config.Digest != "" {
+		b[manifest.Config.Digest] = manifest.Config.Size
+	}
+
+	for _, layer := range manifest.Layers {
+		b[layer.Digest] = layer.Size
+	}
+}
+
+// versionManifests maps the digests of the image manifests a version
+// reaches, itself or the manifests in its index, to the blobs they reference
+type versionManifests map[string]blobSizes
+
+// storageCache keeps the manifests of each package version by digest.
+// Manifests are immutable, so a version is only walked once.
+type storageCache struct {
+	mu       sync.Mutex
+	versions map[string]map[string]versionManifests // owner/repo -> digest -> manifests
+}
+
+func newStorageCache() *storageCache {
+	return &storageCache{
+		versions: make(map[string]map[string]versionManifests),
+	}
+}
+
+func (c *storageCache) get(owner, repo, digest string) (versionManifests, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	manifests, ok := c.versions[owner+"/"+repo][digest]
+
+	return manifests, ok
+}
+
+// replace swaps the package's cached versions for its current ones, so
+// deleted versions are dropped
+func (c *storageCache) replace(owner, repo string, versions map[string]versionManifests) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.versions[owner+"/"+repo] = versions
+}
+
+// forget drops the cached versions of a package that is no longer collected
+func (c *storageCache) forget(owner, repo string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.versions, owner+"/"+repo)
+}
+
+// getVersionManifests walks the manifest at digest and, for image indexes,
+// every manifest in it, returning the blobs each image manifest references
+func (rc *registryClient) getVersionManifests(ctx context.Context, repository, digest string) (versionManifests, error) {
+	manifest, _, err := rc.getManifest(ctx, repository, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(versionManifests)
+
+	if !manifest.isIndex() {
+		manifests[digest] = make(blobSizes)
+		manifests[digest].add(manifest)
+
+		return manifests, nil
+	}
+
+	// Attestations are stored alongside the platform images, so they count too
+	for _, descriptor := range manifest.Manifests {
+		child, _, err := rc.getManifest(ctx, repository, descriptor.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk index %s: %w", digest, err)
+		}
+
+		manifests[descriptor.Digest] = make(blobSizes)
+		manifests[descriptor.Digest].add(child)
+	}
+
+	return manifests, nil
+}
+
+// packageStorage is the storage used by a package's versions
+type packageStorage struct {
+	total  int64 // Every blob, counted once
+	shared int64 // Blobs used by more than one image manifest, counted once
+}
+
+// summarizeStorage deduplicates the blobs of every version. GHCR lists an
+// index and each of its platform manifests as separate versions, so sharing
+// is counted between distinct image manifests rather than versions.
+func summarizeStorage(versions map[string]versionManifests) packageStorage {
+	manifests := make(map[string]blobSizes)
+
+	for _, versionManifests := range versions {
+		for digest, blobs := range versionManifests {
+			manifests[digest] = blobs
+		}
+	}
+
+	sizes := make(map[string]int64)
+	users := make(map[string]int)
+
+	for _, blobs := range manifests {
+		for digest, size := range blobs {
+			sizes[digest] = size
+			users[digest]++
+		}
+	}
+
+	var storage packageStorage
+
+	for digest, size := range sizes {
+		storage.total += size
+
+		if users[digest] > 1 {
+			storage.shared += size
+		}
+	}
+
+	return storage
+}
+
+// updateStorageMetrics exports the deduplicated storage used by all versions
+// of a package, walking the manifests of versions not seen before. At most
+// registry.storage_walks_per_cycle versions are walked each cycle, so a large
+// package's first walk doesn't use up the collection deadline. Versions that
+// can't be walked yet are left for the next cycle, and the metrics are only
+// updated once every version has been walked so a partial sum isn't exported.
+// A truncated listing never has every version, so its series are removed.
+func (gc *GHCRCollector) updateStorageMetrics(ctx context.Context, pkg config.PackageGroup, versions []GHCRVersionResponse, truncated bool) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	if truncated {
+		gc.metrics.PackageStorageBytesGauge.Delete(labels)
+		gc.metrics.PackageStorageSharedBytesGauge.Delete(labels)
+
+		slog.Debug("Not exporting storage metrics for a truncated version listing", "owner", pkg.Owner, "package", pkg.Repo)
+
+		return
+	}
+
+	tracer := gc.app.GetTracer()
+
+	var (
+		collectorSpan *tracing.CollectorSpan
+		spanCtx       context.Context //nolint:contextcheck // Extracting context from span for child operations
+	)
+
+	if tracer != nil && tracer.IsEnabled() {
+		collectorSpan = tracer.NewCollectorSpan(ctx, "ghcr-collector", "collect-storage")
+		collectorSpan.SetAttributes(
+			attribute.String("package.owner", pkg.Owner),
+			attribute.String("package.repo", pkg.Repo),
+		)
+
+		spanCtx = collectorSpan.Context()
+		defer collectorSpan.End()
+	} else {
+		spanCtx = ctx
+	}
+
+	repository := registryRepository(pkg.Owner, pkg.Repo)
+	byDigest := make(map[string]versionManifests)
+	reached := make(map[string]blobSizes) // Image manifests reached by any version in this cycle
+	walkLimit := gc.config.GetStorageWalksPerCycle()
+	fetched, failed, deferred := 0, 0, 0
+
+	for _, version := range versions {
+		// Container versions are named after their manifest digest
+		digest := version.Name
+		if !strings.HasPrefix(digest, "sha256:") {
+			continue
+		}
+
+		if _, ok := byDigest[digest]; ok {
+			continue
+		}
+
+		manifests, ok := gc.storage.get(pkg.Owner, pkg.Repo, digest)
+
+		// Platform manifests are usually reached through their index first
+		if blobs, seen := reached[digest]; !ok && seen {
+			manifests, ok = versionManifests{digest: blobs}, true
+		}
+
+		if !ok {
+			// Past the walk limit or the deadline, only cached versions can
+			// be counted
+			if fetched+failed >= walkLimit || spanCtx.Err() != nil {
+				deferred++
+				continue
+			}
+
+			var err error
+
+			manifests, err = gc.registry.getVersionManifests(spanCtx, repository, digest)
+			if err != nil {
+				slog.Warn("Failed to get version manifests", "owner", pkg.Owner, "package", pkg.Repo, "digest", digest, "error", err)
+
+				if collectorSpan != nil {
+					collectorSpan.RecordError(err, attribute.String("digest", digest))
+				}
+
+				failed++
+
+				continue
+			}
+
+			fetched++
+		}
+
+		byDigest[digest] = manifests
+
+		for manifestDigest, blobs := range manifests {
+			reached[manifestDigest] = blobs
+		}
+	}
+
+	// Versions walked so far are kept, so a large package is walked over
+	// several cycles if need be
+	gc.storage.replace(pkg.Owner, pkg.Repo, byDigest)
+
+	if collectorSpan != nil {
+		collectorSpan.SetAttributes(
+			attribute.Int("versions.count", len(byDigest)),
+			attribute.Int("versions.fetched", fetched),
+			attribute.Int("versions.failed", failed),
+			attribute.Int("versions.deferred", deferred),
+		)
+	}
+
+	if failed > 0 || deferred > 0 {
+		slog.Warn("Not updating storage metrics until every version has been walked",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"versions", len(byDigest),
+			"fetched", fetched,
+			"failed", failed,
+			"deferred", deferred)
+
+		return
+	}
+
+	storage := summarizeStorage(byDigest)
+
+	gc.metrics.PackageStorageBytesGauge.With(labels).Set(float64(storage.total))
+	gc.metrics.PackageStorageSharedBytesGauge.With(labels).Set(float64(storage.shared))
+
+	if collectorSpan != nil {
+		collectorSpan.SetAttributes(attribute.Int64("storage.bytes", storage.total))
+	}
+
+	slog.Debug("Updated storage metrics",
+		"owner", pkg.Owner,
+		"package", pkg.Repo,
+		"versions", len(byDigest),
+		"fetched", fetched,
+		"bytes", storage.total,
+		"shared_bytes", storage.shared)
+}
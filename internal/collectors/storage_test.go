@@ -0,0 +1,227 @@
+package collectors
+
+import (
+	"context"
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpdateStorageMetrics(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	// Both images have the same first layer, but different configs
+	first := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{Created: "2026-10-01T12:00:00Z"}, 1000, 2000)
+	second := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{Created: "2026-10-02T12:00:00Z"}, 1000, 3000)
+	index := registry.addIndex(repository, []ociDescriptor{first})
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+
+	configSize := func(descriptor ociDescriptor) int64 {
+		manifest, _, err := collector.registry.getManifest(context.Background(), repository, descriptor.Digest)
+		if err != nil {
+			t.Fatalf("Failed to get manifest: %v", err)
+		}
+
+		return manifest.Config.Size
+	}
+
+	firstConfig, secondConfig := configSize(first), configSize(second)
+
+	// Versions not named after a digest are skipped
+	versions := []GHCRVersionResponse{
+		imageVersion(1, index, "v1.0.0"),
+		imageVersion(2, second, "v1.1.0"),
+		testVersion(3, "2026-10-01T12:00:00Z", "legacy"),
+	}
+
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	if got, expected := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels)), float64(firstConfig+secondConfig+6000); got != expected {
+		t.Errorf("Expected %v bytes of storage with the shared layer counted once, got %v", expected, got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageStorageSharedBytesGauge.With(labels)); got != 1000 {
+		t.Errorf("Expected the 1000 byte shared layer, got %v", got)
+	}
+
+	// Unchanged versions are served from the cache
+	requests := registry.manifestRequests.Load()
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	if got := registry.manifestRequests.Load(); got != requests {
+		t.Errorf("Expected cached versions not to be walked again, got %d more manifest requests", got-requests)
+	}
+
+	// Deleted versions no longer count
+	collector.updateStorageMetrics(context.Background(), pkg, versions[1:], false)
+
+	if got, expected := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels)), float64(secondConfig+4000); got != expected {
+		t.Errorf("Expected %v bytes after the first version was deleted, got %v", expected, got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageStorageSharedBytesGauge.With(labels)); got != 0 {
+		t.Errorf("Expected nothing to be shared by a single version, got %v", got)
+	}
+}
+
+func TestUpdateStorageMetricsMultiPlatform(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	amd64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 1000, 2000)
+	arm64 := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 1500)
+	index := registry.addIndex(repository, []ociDescriptor{amd64, arm64})
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+
+	var configs int64
+
+	for _, descriptor := range []ociDescriptor{amd64, arm64} {
+		manifest, _, err := collector.registry.getManifest(context.Background(), repository, descriptor.Digest)
+		if err != nil {
+			t.Fatalf("Failed to get manifest: %v", err)
+		}
+
+		configs += manifest.Config.Size
+	}
+
+	// GHCR lists the index and each platform manifest as separate versions
+	requests := registry.manifestRequests.Load()
+	collector.updateStorageMetrics(context.Background(), pkg, []GHCRVersionResponse{
+		imageVersion(3, index, "v1.0.0"),
+		imageVersion(2, arm64),
+		imageVersion(1, amd64),
+	}, false)
+
+	if got, expected := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels)), float64(configs+4500); got != expected {
+		t.Errorf("Expected %v bytes of storage, got %v", expected, got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageStorageSharedBytesGauge.With(labels)); got != 0 {
+		t.Errorf("Expected platform manifests listed as versions not to count as shared, got %v", got)
+	}
+
+	if got := registry.manifestRequests.Load() - requests; got != 3 {
+		t.Errorf("Expected platform versions reached through the index not to be fetched again, got %d manifest requests", got)
+	}
+}
+
+func TestUpdateStorageMetricsKeepsValuesWhenIncomplete(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	first := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 1000)
+	second := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 2000)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+
+	versions := []GHCRVersionResponse{imageVersion(1, first, "v1.0.0")}
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	before := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels))
+
+	// A version that can't be walked leaves the previous values alone
+	missing := GHCRVersionResponse{ID: 2, Name: "sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+	collector.updateStorageMetrics(context.Background(), pkg, append(versions, missing), false)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels)); got != before {
+		t.Errorf("Expected %v bytes to be kept when a version fails, got %v", before, got)
+	}
+
+	// As does running out of time, though cached versions are still kept
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	requests := registry.manifestRequests.Load()
+	collector.updateStorageMetrics(ctx, pkg, append(versions, imageVersion(2, second, "v1.1.0")), false)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageStorageBytesGauge.With(labels)); got != before {
+		t.Errorf("Expected %v bytes to be kept past the deadline, got %v", before, got)
+	}
+
+	if got := registry.manifestRequests.Load(); got != requests {
+		t.Errorf("Expected no requests past the deadline, got %d", got-requests)
+	}
+
+	if _, ok := collector.storage.get("d0ugal", "mqtt-exporter", first.Digest); !ok {
+		t.Error("Expected the cached version to be kept past the deadline")
+	}
+}
+
+func TestUpdateStorageMetricsWalkLimit(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	first := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 1000)
+	second := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 2000)
+	third := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "arm64"}, ociImageConfig{}, 3000)
+
+	collector := newImageTestCollector(t, registry)
+	collector.config.Registry.StorageWalksPerCycle = 2
+
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	versions := []GHCRVersionResponse{
+		imageVersion(3, third, "v1.2.0"),
+		imageVersion(2, second, "v1.1.0"),
+		imageVersion(1, first, "v1.0.0"),
+	}
+
+	// The first cycle walks up to the limit and leaves the rest for later
+	requests := registry.manifestRequests.Load()
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	if got := registry.manifestRequests.Load() - requests; got != 2 {
+		t.Errorf("Expected 2 versions to be walked in the first cycle, got %d manifest requests", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageStorageBytesGauge); got != 0 {
+		t.Errorf("Expected no storage series before every version has been walked, got %d", got)
+	}
+
+	// The next cycle walks the remaining version and exports the total
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageStorageBytesGauge); got != 1 {
+		t.Errorf("Expected a storage series once every version has been walked, got %d", got)
+	}
+}
+
+func TestUpdateStorageMetricsTruncated(t *testing.T) {
+	registry := newTestRegistry(t)
+	repository := registryRepository("d0ugal", "mqtt-exporter")
+
+	first := registry.addImage(repository, ociPlatform{OS: "linux", Architecture: "amd64"}, ociImageConfig{}, 1000)
+
+	collector := newImageTestCollector(t, registry)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	versions := []GHCRVersionResponse{imageVersion(1, first, "v1.0.0")}
+
+	collector.updateStorageMetrics(context.Background(), pkg, versions, false)
+
+	// A truncated listing is missing versions, so its total would be too low
+	requests := registry.manifestRequests.Load()
+	collector.updateStorageMetrics(context.Background(), pkg, versions, true)
+
+	for _, vec := range []*prometheus.GaugeVec{
+		collector.metrics.PackageStorageBytesGauge,
+		collector.metrics.PackageStorageSharedBytesGauge,
+	} {
+		if got := testutil.CollectAndCount(vec); got != 0 {
+			t.Errorf("Expected the storage series to be removed for a truncated listing, got %d", got)
+		}
+	}
+
+	if got := registry.manifestRequests.Load(); got != requests {
+		t.Errorf("Expected a truncated listing not to be walked, got %d manifest requests", got-requests)
+	}
+}
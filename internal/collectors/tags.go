@@ -0,0 +1,184 @@
+package collectors
+
+import (
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// tagTarget is the version a tag currently points to
+type tagTarget struct {
+	versionID int
+	digest    string // Manifest digest, the version's name for container packages
+	created   time.Time
+	updated   time.Time
+}
+
+// parseVersionTime parses a GitHub API timestamp, returning the zero time if
+// it is missing or malformed
+func parseVersionTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return parsed
+}
+
+// versionUpdated returns when a version was last changed, which includes
+// tags being added to it, falling back to when it was created
+func versionUpdated(version GHCRVersionResponse) time.Time {
+	if updated := parseVersionTime(version.UpdatedAt); !updated.IsZero() {
+		return updated
+	}
+
+	return parseVersionTime(version.CreatedAt)
+}
+
+// currentTags maps each tag to the version carrying it. A tag should only be
+// on one version; if it appears on several, the most recently updated wins.
+func currentTags(versions []GHCRVersionResponse) map[string]tagTarget {
+	tags := make(map[string]tagTarget)
+
+	for _, version := range versions {
+		updated := versionUpdated(version)
+
+		for _, tag := range version.Metadata.Container.Tags {
+			if existing, ok := tags[tag]; ok && !updated.After(existing.updated) {
+				continue
+			}
+
+			tags[tag] = tagTarget{
+				versionID: version.ID,
+				digest:    version.Name,
+				created:   parseVersionTime(version.CreatedAt),
+				updated:   updated,
+			}
+		}
+	}
+
+	return tags
+}
+
+// updateTagMetrics exports the tags of a package that match the group's tag
+// patterns, replacing any series for tags that moved or were removed
+func (gc *GHCRCollector) updateTagMetrics(pkg config.PackageGroup, tags map[string]tagTarget) {
+	packageLabels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	gc.metrics.PackageTagInfoGauge.DeletePartialMatch(packageLabels)
+	gc.metrics.PackageTagUpdatedGauge.DeletePartialMatch(packageLabels)
+
+	tracked := 0
+
+	for tag, target := range tags {
+		if !pkg.TracksTag(tag) {
+			continue
+		}
+
+		tracked++
+
+		gc.metrics.PackageTagInfoGauge.With(prometheus.Labels{
+			"owner":      pkg.Owner,
+			"repo":       pkg.Repo,
+			"tag":        tag,
+			"version_id": strconv.Itoa(target.versionID),
+		}).Set(1)
+
+		if !target.updated.IsZero() {
+			gc.metrics.PackageTagUpdatedGauge.With(prometheus.Labels{
+				"owner": pkg.Owner,
+				"repo":  pkg.Repo,
+				"tag":   tag,
+			}).Set(float64(target.updated.Unix()))
+		}
+	}
+
+	slog.Debug("Updated tag metrics", "owner", pkg.Owner, "package", pkg.Repo, "tracked_tags", tracked)
+}
+
+// tagMove is a tag that now points to a different version than before
+type tagMove struct {
+	tag               string
+	previousVersionID int
+	versionID         int
+}
+
+// tagTracker remembers which version each tag of a package pointed to, so
+// tags that are re-pushed to a different version can be reported
+type tagTracker struct {
+	mu    sync.Mutex
+	known map[string]map[string]int // owner/repo -> tag -> version ID
+}
+
+func newTagTracker() *tagTracker {
+	return &tagTracker{
+		known: make(map[string]map[string]int),
+	}
+}
+
+// update records the current tags of a package and returns the tags that
+// moved. Tags missing from the listing are remembered, so a tag that is
+// deleted and later pushed to another version still counts as a move.
+func (t *tagTracker) update(owner, repo string, tags map[string]tagTarget) []tagMove {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := owner + "/" + repo
+
+	known, ok := t.known[key]
+	if !ok {
+		known = make(map[string]int, len(tags))
+		t.known[key] = known
+	}
+
+	var moves []tagMove
+
+	for tag, target := range tags {
+		if previous, seen := known[tag]; seen && previous != target.versionID {
+			moves = append(moves, tagMove{tag: tag, previousVersionID: previous, versionID: target.versionID})
+		}
+
+		known[tag] = target.versionID
+	}
+
+	return moves
+}
+
+// forget drops the known tags of a package that is no longer collected
+func (t *tagTracker) forget(owner, repo string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.known, owner+"/"+repo)
+}
+
+// detectTagMoves counts and logs tags that moved to a different version since
+// the previous collection, ignoring tags the group doesn't track or expects
+// to be mutable
+func (gc *GHCRCollector) detectTagMoves(pkg config.PackageGroup, tags map[string]tagTarget) {
+	for _, move := range gc.tags.update(pkg.Owner, pkg.Repo, tags) {
+		if !pkg.TracksTag(move.tag) || pkg.IsMutableTag(move.tag) {
+			continue
+		}
+
+		slog.Warn("Tag moved to a different version",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"tag", move.tag,
+			"previous_version_id", move.previousVersionID,
+			"version_id", move.versionID)
+
+		gc.metrics.PackageTagMovesCounter.With(prometheus.Labels{
+			"owner": pkg.Owner,
+			"repo":  pkg.Repo,
+			"tag":   move.tag,
+		}).Inc()
+	}
+}
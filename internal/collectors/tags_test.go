@@ -0,0 +1,141 @@
+package collectors
+
+import (
+	"testing"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func testVersion(id int, updatedAt string, tags ...string) GHCRVersionResponse {
+	version := GHCRVersionResponse{ID: id, CreatedAt: "2025-10-01T12:00:00Z", UpdatedAt: updatedAt}
+	version.Metadata.Container.Tags = tags
+
+	return version
+}
+
+func TestCurrentTags(t *testing.T) {
+	tags := currentTags([]GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0", "latest"),
+		testVersion(2, "2025-10-02T12:00:00Z", "v1.1.0", "latest"),
+		testVersion(3, "", "v0.9.0"),
+	})
+
+	if len(tags) != 4 {
+		t.Fatalf("Expected 4 tags, got %d", len(tags))
+	}
+
+	if tags["latest"].versionID != 2 {
+		t.Errorf("Expected latest to point at the most recently updated version, got %d", tags["latest"].versionID)
+	}
+
+	// Versions without updated_at fall back to created_at
+	if tags["v0.9.0"].updated.IsZero() {
+		t.Error("Expected v0.9.0 to fall back to its created_at timestamp")
+	}
+}
+
+func TestUpdateTagMetrics(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", Tags: []string{"latest", "/^v[0-9]+\\.[0-9]+\\.[0-9]+$/"}}
+
+	collector.updateTagMetrics(pkg, currentTags([]GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0"),
+		testVersion(2, "2025-10-02T12:00:00Z", "v1.1.0", "latest"),
+		testVersion(3, "2025-10-03T12:00:00Z", "pr-42", "sha-abc123"),
+	}))
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageTagInfoGauge); got != 3 {
+		t.Errorf("Expected only the 3 allowed tags to be exported, got %d", got)
+	}
+
+	updated := testutil.ToFloat64(collector.metrics.PackageTagUpdatedGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "latest",
+	}))
+	if updated != 1759406400 {
+		t.Errorf("Expected latest updated timestamp 1759406400, got %v", updated)
+	}
+
+	// latest moves to a new version
+	collector.updateTagMetrics(pkg, currentTags([]GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0"),
+		testVersion(2, "2025-10-02T12:00:00Z", "v1.1.0"),
+		testVersion(4, "2025-10-04T12:00:00Z", "v1.2.0", "latest"),
+	}))
+
+	if collector.metrics.PackageTagInfoGauge.Delete(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "latest", "version_id": "2",
+	}) {
+		t.Error("Expected the series for latest's previous version to have been removed")
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageTagInfoGauge.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "latest", "version_id": "4",
+	})); got != 1 {
+		t.Errorf("Expected latest to point at version 4, got %v", got)
+	}
+}
+
+func TestDetectTagMoves(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", MutableTags: []string{"latest", "main"}}
+
+	detect := func(versions ...GHCRVersionResponse) {
+		collector.detectTagMoves(pkg, currentTags(versions))
+	}
+
+	detect(
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0"),
+		testVersion(2, "2025-10-02T12:00:00Z", "v1.1.0", "latest", "main"),
+	)
+
+	// latest and main move as expected, v1.1.0 is re-pushed
+	detect(
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0"),
+		testVersion(3, "2025-10-03T12:00:00Z", "v1.1.0", "latest", "main"),
+	)
+
+	// v1.0.0 is deleted and later pushed again to a new version
+	detect(testVersion(3, "2025-10-03T12:00:00Z", "v1.1.0", "latest", "main"))
+	detect(
+		testVersion(3, "2025-10-03T12:00:00Z", "v1.1.0", "latest", "main"),
+		testVersion(4, "2025-10-04T12:00:00Z", "v1.0.0"),
+	)
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageTagMovesCounter); got != 2 {
+		t.Errorf("Expected moves to be counted for 2 tags, got %d", got)
+	}
+
+	for _, tag := range []string{"v1.0.0", "v1.1.0"} {
+		if got := testutil.ToFloat64(collector.metrics.PackageTagMovesCounter.With(prometheus.Labels{
+			"owner": "d0ugal", "repo": "mqtt-exporter", "tag": tag,
+		})); got != 1 {
+			t.Errorf("Expected 1 move for %s, got %v", tag, got)
+		}
+	}
+}
+
+func TestDetectTagMovesSkipsUntrackedTags(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter", Tags: []string{"v*"}}
+
+	collector.detectTagMoves(pkg, currentTags([]GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0", "pr-1234", "edge"),
+	}))
+
+	// Re-pushed PR and edge tags are outside the allowlist, v1.0.0 isn't
+	collector.detectTagMoves(pkg, currentTags([]GHCRVersionResponse{
+		testVersion(2, "2025-10-02T12:00:00Z", "v1.0.0", "pr-1234", "edge"),
+	}))
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageTagMovesCounter); got != 1 {
+		t.Errorf("Expected moves to only be counted for the tracked tag, got %d series", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageTagMovesCounter.With(prometheus.Labels{
+		"owner": "d0ugal", "repo": "mqtt-exporter", "tag": "v1.0.0",
+	})); got != 1 {
+		t.Errorf("Expected 1 move for v1.0.0, got %v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package collectors
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// updateVersionMetrics exports how many versions of a package are tagged and
+// untagged, and how long the oldest untagged version has been left behind.
+// truncated marks a listing cut off before its oldest versions.
+func (gc *GHCRCollector) updateVersionMetrics(pkg config.PackageGroup, versions []GHCRVersionResponse, truncated bool, now time.Time) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	var (
+		tagged, untagged int
+		oldestUntagged   time.Time
+	)
+
+	for _, version := range versions {
+		if len(version.Metadata.Container.Tags) > 0 {
+			tagged++
+			continue
+		}
+
+		untagged++
+
+		created := parseVersionTime(version.CreatedAt)
+		if !created.IsZero() && (oldestUntagged.IsZero() || created.Before(oldestUntagged)) {
+			oldestUntagged = created
+		}
+	}
+
+	gc.metrics.PackageTaggedVersionsGauge.With(labels).Set(float64(tagged))
+	gc.metrics.PackageUntaggedVersionsGauge.With(labels).Set(float64(untagged))
+	gc.metrics.PackageVersionsTruncatedGauge.With(labels).Set(boolToFloat(truncated))
+
+	// Without untagged versions there is no age to report, and a truncated
+	// listing is missing the oldest versions so its age would be wrong
+	if oldestUntagged.IsZero() || truncated {
+		gc.metrics.PackageOldestUntaggedVersionGauge.Delete(labels)
+	} else {
+		gc.metrics.PackageOldestUntaggedVersionGauge.With(labels).Set(now.Sub(oldestUntagged).Seconds())
+	}
+
+	if truncated {
+		slog.Warn("Package version listing was truncated, raise github.max_pages to count every version",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"versions", len(versions),
+			"max_pages", gc.config.GetMaxPages())
+	}
+
+	slog.Debug("Updated version metrics",
+		"owner", pkg.Owner,
+		"package", pkg.Repo,
+		"tagged_versions", tagged,
+		"untagged_versions", untagged)
+}
+
+// versionTracker remembers the version IDs seen for each package in the last
+// collection, so versions published or deleted in between can be counted
+type versionTracker struct {
+	mu    sync.Mutex
+	known map[string]map[int]struct{} // owner/repo -> version IDs
+}
+
+func newVersionTracker() *versionTracker {
+	return &versionTracker{
+		known: make(map[string]map[int]struct{}),
+	}
+}
+
+// update replaces the package's known versions and returns how many were
+// published and deleted since the last update. ok is false the first time a
+// package is seen, when there is nothing to compare against.
+//
+// A truncated listing only holds the newest versions, so versions sliding
+// past its oldest entry are neither published nor deleted and are ignored.
+func (t *versionTracker) update(owner, repo string, versions []GHCRVersionResponse, truncated bool) (published, deleted int, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := make(map[int]struct{}, len(versions))
+	for _, version := range versions {
+		current[version.ID] = struct{}{}
+	}
+
+	key := owner + "/" + repo
+	previous, ok := t.known[key]
+	t.known[key] = current
+
+	if !ok {
+		return 0, 0, false
+	}
+
+	floor := 0
+	if truncated {
+		floor = max(minVersionID(current), minVersionID(previous))
+	}
+
+	for id := range current {
+		if _, seen := previous[id]; !seen && id >= floor {
+			published++
+		}
+	}
+
+	for id := range previous {
+		if _, present := current[id]; !present && id >= floor {
+			deleted++
+		}
+	}
+
+	return published, deleted, true
+}
+
+// forget drops the known versions of a package that is no longer collected
+func (t *versionTracker) forget(owner, repo string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.known, owner+"/"+repo)
+}
+
+func minVersionID(ids map[int]struct{}) int {
+	lowest := 0
+	for id := range ids {
+		if lowest == 0 || id < lowest {
+			lowest = id
+		}
+	}
+
+	return lowest
+}
+
+// updateVersionEvents counts the versions of a package published and deleted
+// since the previous collection
+func (gc *GHCRCollector) updateVersionEvents(pkg config.PackageGroup, versions []GHCRVersionResponse, truncated bool) {
+	labels := prometheus.Labels{
+		"owner": pkg.Owner,
+		"repo":  pkg.Repo,
+	}
+
+	published, deleted, ok := gc.versions.update(pkg.Owner, pkg.Repo, versions, truncated)
+	if !ok {
+		// Export zeroes on first sight so increase() works from the next change
+		gc.metrics.PackageVersionsPublishedCounter.With(labels).Add(0)
+		gc.metrics.PackageVersionsDeletedCounter.With(labels).Add(0)
+
+		return
+	}
+
+	gc.metrics.PackageVersionsPublishedCounter.With(labels).Add(float64(published))
+	gc.metrics.PackageVersionsDeletedCounter.With(labels).Add(float64(deleted))
+
+	if published > 0 || deleted > 0 {
+		slog.Info("Package versions changed",
+			"owner", pkg.Owner,
+			"package", pkg.Repo,
+			"published", published,
+			"deleted", deleted)
+	}
+}
@@ -0,0 +1,152 @@
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	"ghcr-exporter/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpdateVersionMetrics(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+	now := time.Date(2025, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	untagged := func(id int, createdAt string) GHCRVersionResponse {
+		return GHCRVersionResponse{ID: id, CreatedAt: createdAt}
+	}
+
+	collector.updateVersionMetrics(pkg, []GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0", "latest"),
+		testVersion(2, "2025-10-02T12:00:00Z", "v0.9.0"),
+		untagged(3, "2025-10-08T12:00:00Z"),
+		untagged(4, "2025-10-09T12:00:00Z"),
+		untagged(5, "not-a-timestamp"),
+	}, false, now)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageTaggedVersionsGauge.With(labels)); got != 2 {
+		t.Errorf("Expected 2 tagged versions, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageUntaggedVersionsGauge.With(labels)); got != 3 {
+		t.Errorf("Expected 3 untagged versions, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageOldestUntaggedVersionGauge.With(labels)); got != (48 * time.Hour).Seconds() {
+		t.Errorf("Expected the oldest untagged version to be 2 days old, got %vs", got)
+	}
+
+	// Cleaning up every untagged version removes the age series
+	collector.updateVersionMetrics(pkg, []GHCRVersionResponse{
+		testVersion(1, "2025-10-01T12:00:00Z", "v1.0.0", "latest"),
+	}, false, now)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageUntaggedVersionsGauge.With(labels)); got != 0 {
+		t.Errorf("Expected 0 untagged versions, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageOldestUntaggedVersionGauge); got != 0 {
+		t.Errorf("Expected no oldest untagged version series, got %d", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsTruncatedGauge.With(labels)); got != 0 {
+		t.Errorf("Expected the listing not to be truncated, got %v", got)
+	}
+
+	// A truncated listing is flagged, and its oldest fetched untagged version
+	// isn't reported as the oldest
+	collector.updateVersionMetrics(pkg, []GHCRVersionResponse{
+		untagged(6, "2025-10-08T12:00:00Z"),
+	}, true, now)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsTruncatedGauge.With(labels)); got != 1 {
+		t.Errorf("Expected the listing to be truncated, got %v", got)
+	}
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageOldestUntaggedVersionGauge); got != 0 {
+		t.Errorf("Expected no oldest untagged version series for a truncated listing, got %d", got)
+	}
+}
+
+func versionsWithIDs(ids ...int) []GHCRVersionResponse {
+	versions := make([]GHCRVersionResponse, 0, len(ids))
+	for _, id := range ids {
+		versions = append(versions, GHCRVersionResponse{ID: id})
+	}
+
+	return versions
+}
+
+func TestVersionTrackerUpdate(t *testing.T) {
+	tracker := newVersionTracker()
+
+	if _, _, ok := tracker.update("d0ugal", "mqtt-exporter", versionsWithIDs(1, 2, 3), false); ok {
+		t.Fatal("Expected the first update to have nothing to compare against")
+	}
+
+	published, deleted, ok := tracker.update("d0ugal", "mqtt-exporter", versionsWithIDs(2, 3, 4, 5), false)
+	if !ok || published != 2 || deleted != 1 {
+		t.Errorf("Expected 2 published and 1 deleted, got %d published and %d deleted", published, deleted)
+	}
+
+	// Version 2 drops off the end of a truncated listing when 6 is published
+	published, deleted, _ = tracker.update("d0ugal", "mqtt-exporter", versionsWithIDs(3, 4, 5, 6), true)
+	if published != 1 || deleted != 0 {
+		t.Errorf("Expected 1 published and 0 deleted from a truncated listing, got %d published and %d deleted", published, deleted)
+	}
+
+	// Deleting 5 lets an older version slide into the truncated listing
+	published, deleted, _ = tracker.update("d0ugal", "mqtt-exporter", versionsWithIDs(2, 3, 4, 6), true)
+	if published != 0 || deleted != 1 {
+		t.Errorf("Expected 0 published and 1 deleted from a truncated listing, got %d published and %d deleted", published, deleted)
+	}
+
+	tracker.forget("d0ugal", "mqtt-exporter")
+
+	if _, _, ok := tracker.update("d0ugal", "mqtt-exporter", versionsWithIDs(7), false); ok {
+		t.Error("Expected a forgotten package to start again from scratch")
+	}
+}
+
+func TestUpdateVersionEvents(t *testing.T) {
+	collector := newMetricsTestCollector(t)
+	pkg := config.PackageGroup{Owner: "d0ugal", Repo: "mqtt-exporter"}
+	labels := prometheus.Labels{"owner": "d0ugal", "repo": "mqtt-exporter"}
+
+	collector.updateVersionEvents(pkg, versionsWithIDs(1, 2, 3), false)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsPublishedCounter.With(labels)); got != 0 {
+		t.Errorf("Expected existing versions not to count as published, got %v", got)
+	}
+
+	collector.updateVersionEvents(pkg, versionsWithIDs(2, 3, 4), false)
+	collector.updateVersionEvents(pkg, versionsWithIDs(3, 4, 5), false)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsPublishedCounter.With(labels)); got != 2 {
+		t.Errorf("Expected 2 published versions, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsDeletedCounter.With(labels)); got != 2 {
+		t.Errorf("Expected 2 deleted versions, got %v", got)
+	}
+
+	// Version 3 dropping off a truncated listing isn't a deletion
+	collector.updateVersionEvents(pkg, versionsWithIDs(4, 5, 6), true)
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsPublishedCounter.With(labels)); got != 3 {
+		t.Errorf("Expected 3 published versions, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(collector.metrics.PackageVersionsDeletedCounter.With(labels)); got != 2 {
+		t.Errorf("Expected 2 deleted versions after a truncated listing, got %v", got)
+	}
+
+	collector.deletePackageMetrics("d0ugal", "mqtt-exporter")
+
+	if got := testutil.CollectAndCount(collector.metrics.PackageVersionsPublishedCounter); got != 0 {
+		t.Errorf("Expected the published counter to be removed with the package, got %d series", got)
+	}
+}
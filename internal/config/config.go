@@ -2,8 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	promexporter_config "github.com/d0ugal/promexporter/config"
@@ -16,17 +20,116 @@ type Duration = promexporter_config.Duration
 type Config struct {
 	promexporter_config.BaseConfig
 
-	GitHub   GitHubConfig   `yaml:"github"`
-	Packages []PackageGroup `yaml:"packages"`
+	GitHub    GitHubConfig    `yaml:"github"`
+	Registry  RegistryConfig  `yaml:"registry"`
+	Collector CollectorConfig `yaml:"collector"`
+	Packages  []PackageGroup  `yaml:"packages"`
 }
 
 type GitHubConfig struct {
-	Token promexporter_config.SensitiveString `yaml:"token"`
+	Token    promexporter_config.SensitiveString `yaml:"token"`
+	MaxPages int                                 `yaml:"max_pages,omitempty"` // Cap on pages followed for paginated API listings
+}
+
+// DefaultRegistryURL is the container registry serving GitHub packages
+const DefaultRegistryURL = "https://ghcr.io"
+
+// RegistryConfig controls access to the OCI registry serving the images
+type RegistryConfig struct {
+	Enabled              bool   `yaml:"enabled"`                           // Fetch image manifests for tracked tags
+	URL                  string `yaml:"url,omitempty"`                     // Registry base URL, defaults to https://ghcr.io
+	StorageWalksPerCycle int    `yaml:"storage_walks_per_cycle,omitempty"` // Cap on versions walked for storage metrics per package each cycle
+}
+
+// CollectorConfig controls how packages are collected
+type CollectorConfig struct {
+	Concurrency      int         `yaml:"concurrency,omitempty"`       // Maximum packages collected at once across all owners
+	OwnerConcurrency int         `yaml:"owner_concurrency,omitempty"` // Maximum packages collected at once for a single owner
+	Retry            RetryConfig `yaml:"retry"`
+	StaleGracePeriod *Duration   `yaml:"stale_grace_period,omitempty"` // How long a vanished package keeps its metrics, nil when unset so 0 can mean immediately
+}
+
+// RetryConfig controls how transient collection failures are retried
+type RetryConfig struct {
+	Attempts  int      `yaml:"attempts,omitempty"`   // Total attempts, including the first
+	BaseDelay Duration `yaml:"base_delay,omitempty"` // Delay before the first retry, doubled for each one after
+	MaxDelay  Duration `yaml:"max_delay,omitempty"`  // Upper bound on the delay between attempts
 }
 
 type PackageGroup struct {
-	Owner string `yaml:"owner"`
-	Repo  string `yaml:"repo,omitempty"` // Optional - if not provided, will discover all repos for owner
+	Owner     string   `yaml:"owner"`
+	OwnerType string   `yaml:"owner_type,omitempty"` // Optional - "user" or "org", looked up once when not provided
+	Repo      string   `yaml:"repo,omitempty"`       // Optional - if not provided, will discover all repos for owner
+	Interval  Duration `yaml:"interval,omitempty"`   // Optional - overrides metrics.collection.default_interval
+	Timeout   Duration `yaml:"timeout,omitempty"`    // Optional - deadline for a single collection cycle, defaults to the interval
+
+	// PublishCadence is how often a new version is expected to be published.
+	// A package that goes longer than this without one is reported overdue.
+	PublishCadence Duration `yaml:"publish_cadence,omitempty"`
+
+	// Discovery filters, only used when Repo is empty. Patterns are globs
+	// unless wrapped in slashes, in which case they are regular expressions.
+	Include    []string `yaml:"include,omitempty"`
+	Exclude    []string `yaml:"exclude,omitempty"`
+	Visibility []string `yaml:"visibility,omitempty"` // public, private and/or internal
+
+	// Tags limits per-tag metrics to matching tags, using the same pattern
+	// syntax as the discovery filters. All tags are exported when empty.
+	Tags []string `yaml:"tags,omitempty"`
+
+	// MutableTags are tags expected to move between versions, such as latest
+	// or main. Any other tag moving is reported. Defaults to latest.
+	MutableTags []string `yaml:"mutable_tags,omitempty"`
+
+	// Platforms are the platforms every tracked tag's image must include, such
+	// as linux/amd64. A platform without a variant matches any variant.
+	Platforms []string `yaml:"platforms,omitempty"`
+
+	// Channels group tags into release channels. Each tag belongs to the
+	// channel of the first rule it matches, if any.
+	Channels []ChannelRule `yaml:"channels,omitempty"`
+}
+
+// ChannelRule maps tags matching a regular expression onto a release channel,
+// for example nightly-2026-10-01 onto nightly
+type ChannelRule struct {
+	Pattern string `yaml:"pattern"`           // Regular expression matched against each tag
+	Channel string `yaml:"channel,omitempty"` // Optional - channel name, may reference capture groups as ${name}; defaults to the "channel" group
+}
+
+// Compile compiles the rule's pattern, checking that a channel name can be
+// derived from it
+func (r ChannelRule) Compile() (*regexp.Regexp, error) {
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Channel == "" && re.SubexpIndex("channel") < 0 {
+		return nil, fmt.Errorf("pattern needs a (?P<channel>...) group when no channel is set")
+	}
+
+	return re, nil
+}
+
+// Kinds of GitHub account that can own packages
+const (
+	OwnerTypeUser = "user"
+	OwnerTypeOrg  = "org"
+)
+
+// Reasons a discovered package can be filtered out of an owner-wide group
+const (
+	FilterReasonVisibility = "visibility"
+	FilterReasonInclude    = "include"
+	FilterReasonExclude    = "exclude"
+	FilterReasonConfigured = "configured" // The repo has its own package group
+)
+
+var validVisibilities = map[string]bool{
+	"public":   true,
+	"private":  true,
+	"internal": true,
 }
 
 // GetName returns a unique name for this package group
@@ -38,6 +141,145 @@ func (p PackageGroup) GetName() string {
 	return p.Owner + "-" + p.Repo
 }
 
+// HasRepoGroup reports whether a repo has its own package group, whose
+// settings take precedence over any owner-wide group that discovers it
+func (c *Config) HasRepoGroup(owner, repo string) bool {
+	for _, group := range c.Packages {
+		if group.Repo != "" && strings.EqualFold(group.Owner, owner) && strings.EqualFold(group.Repo, repo) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasFilters reports whether any discovery filters are configured
+func (p PackageGroup) HasFilters() bool {
+	return len(p.Include) > 0 || len(p.Exclude) > 0 || len(p.Visibility) > 0
+}
+
+// FilterReason returns why a discovered package should be skipped by this
+// group, or an empty string if it should be collected. Patterns are assumed
+// to have passed validation; invalid ones never match.
+func (p PackageGroup) FilterReason(name, visibility string) string {
+	if len(p.Visibility) > 0 && !containsFold(p.Visibility, visibility) {
+		return FilterReasonVisibility
+	}
+
+	if len(p.Include) > 0 && !matchesAnyPattern(p.Include, name) {
+		return FilterReasonInclude
+	}
+
+	if matchesAnyPattern(p.Exclude, name) {
+		return FilterReasonExclude
+	}
+
+	return ""
+}
+
+// TracksTag reports whether per-tag metrics should be exported for tag
+func (p PackageGroup) TracksTag(tag string) bool {
+	return len(p.Tags) == 0 || matchesAnyPattern(p.Tags, tag)
+}
+
+// MissingPlatforms returns the required platforms not among those given
+func (p PackageGroup) MissingPlatforms(platforms []string) []string {
+	var missing []string
+
+	for _, required := range p.Platforms {
+		found := false
+
+		for _, platform := range platforms {
+			if platformMatches(platform, required) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+
+	return missing
+}
+
+// platformMatches reports whether platform satisfies required, where a
+// required platform without a variant matches any variant
+func platformMatches(platform, required string) bool {
+	platform = strings.ToLower(platform)
+	required = strings.ToLower(required)
+
+	return platform == required || strings.HasPrefix(platform, required+"/")
+}
+
+func validPlatform(platform string) bool {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return false
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsMutableTag reports whether tag is expected to move between versions
+func (p PackageGroup) IsMutableTag(tag string) bool {
+	if len(p.MutableTags) == 0 {
+		return tag == "latest"
+	}
+
+	return matchesAnyPattern(p.MutableTags, tag)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAnyPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, err := matchPattern(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern matches name against a glob, or a regular expression when the
+// pattern is written as /regex/
+func matchPattern(pattern, name string) (bool, error) {
+	if expr, ok := regexPattern(pattern); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return false, err
+		}
+
+		return re.MatchString(name), nil
+	}
+
+	return path.Match(pattern, name)
+}
+
+func regexPattern(pattern string) (string, bool) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return pattern[1 : len(pattern)-1], true
+	}
+
+	return "", false
+}
+
 // LoadConfig loads configuration with priority: env vars > yaml file > defaults.
 // The yaml file is optional; if path is empty or the file does not exist it is
 // silently skipped. Environment variables are always applied on top.
@@ -100,6 +342,64 @@ func applyEnvVars(cfg *Config) {
 	if token := os.Getenv("GHCR_EXPORTER_GITHUB_TOKEN"); token != "" {
 		cfg.GitHub.Token = promexporter_config.NewSensitiveString(token)
 	}
+
+	if concurrencyStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_CONCURRENCY"); concurrencyStr != "" {
+		if concurrency, err := strconv.Atoi(concurrencyStr); err == nil {
+			cfg.Collector.Concurrency = concurrency
+		}
+	}
+
+	if ownerConcurrencyStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_OWNER_CONCURRENCY"); ownerConcurrencyStr != "" {
+		if ownerConcurrency, err := strconv.Atoi(ownerConcurrencyStr); err == nil {
+			cfg.Collector.OwnerConcurrency = ownerConcurrency
+		}
+	}
+
+	if attemptsStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_RETRY_ATTEMPTS"); attemptsStr != "" {
+		if attempts, err := strconv.Atoi(attemptsStr); err == nil {
+			cfg.Collector.Retry.Attempts = attempts
+		}
+	}
+
+	if baseDelayStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_RETRY_BASE_DELAY"); baseDelayStr != "" {
+		if baseDelay, err := time.ParseDuration(baseDelayStr); err == nil {
+			cfg.Collector.Retry.BaseDelay = Duration{Duration: baseDelay}
+		}
+	}
+
+	if maxDelayStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_RETRY_MAX_DELAY"); maxDelayStr != "" {
+		if maxDelay, err := time.ParseDuration(maxDelayStr); err == nil {
+			cfg.Collector.Retry.MaxDelay = Duration{Duration: maxDelay}
+		}
+	}
+
+	if gracePeriodStr := os.Getenv("GHCR_EXPORTER_COLLECTOR_STALE_GRACE_PERIOD"); gracePeriodStr != "" {
+		if gracePeriod, err := time.ParseDuration(gracePeriodStr); err == nil {
+			cfg.Collector.StaleGracePeriod = &Duration{Duration: gracePeriod}
+		}
+	}
+
+	if maxPagesStr := os.Getenv("GHCR_EXPORTER_GITHUB_MAX_PAGES"); maxPagesStr != "" {
+		if maxPages, err := strconv.Atoi(maxPagesStr); err == nil {
+			cfg.GitHub.MaxPages = maxPages
+		}
+	}
+
+	if enabledStr := os.Getenv("GHCR_EXPORTER_REGISTRY_ENABLED"); enabledStr != "" {
+		if enabled, err := strconv.ParseBool(enabledStr); err == nil {
+			cfg.Registry.Enabled = enabled
+		}
+	}
+
+	if registryURL := os.Getenv("GHCR_EXPORTER_REGISTRY_URL"); registryURL != "" {
+		cfg.Registry.URL = registryURL
+	}
+
+	if walksStr := os.Getenv("GHCR_EXPORTER_REGISTRY_STORAGE_WALKS_PER_CYCLE"); walksStr != "" {
+		if walks, err := strconv.Atoi(walksStr); err == nil {
+			cfg.Registry.StorageWalksPerCycle = walks
+		}
+	}
 }
 
 // setDefaults sets default values for configuration
@@ -128,6 +428,32 @@ func setDefaults(config *Config) {
 		config.GitHub.Token = promexporter_config.NewSensitiveString(os.Getenv("GITHUB_TOKEN"))
 	}
 
+	if config.GitHub.MaxPages == 0 {
+		config.GitHub.MaxPages = 10
+	}
+
+	if config.Registry.URL == "" {
+		config.Registry.URL = DefaultRegistryURL
+	}
+
+	if config.Registry.StorageWalksPerCycle == 0 {
+		config.Registry.StorageWalksPerCycle = 50
+	}
+
+	if config.Collector.Concurrency == 0 {
+		config.Collector.Concurrency = 8
+	}
+
+	if config.Collector.OwnerConcurrency == 0 {
+		config.Collector.OwnerConcurrency = 4
+	}
+
+	config.Collector.Retry = config.GetRetryConfig()
+
+	if config.Collector.StaleGracePeriod == nil {
+		config.Collector.StaleGracePeriod = &Duration{Duration: time.Hour}
+	}
+
 	if len(config.Packages) == 0 {
 		config.Packages = []PackageGroup{}
 	}
@@ -142,6 +468,16 @@ func (c *Config) loadPackagesFromEnv() {
 	for i := 0; i < 10; i++ { // Support up to 10 packages
 		ownerKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_OWNER", i)
 		repoKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_REPO", i)
+		ownerTypeKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_OWNER_TYPE", i)
+		intervalKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_INTERVAL", i)
+		timeoutKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_TIMEOUT", i)
+		includeKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_INCLUDE", i)
+		excludeKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_EXCLUDE", i)
+		visibilityKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_VISIBILITY", i)
+		tagsKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_TAGS", i)
+		mutableTagsKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_MUTABLE_TAGS", i)
+		publishCadenceKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_PUBLISH_CADENCE", i)
+		platformsKey := fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_PLATFORMS", i)
 
 		owner := os.Getenv(ownerKey)
 		if owner == "" {
@@ -151,10 +487,37 @@ func (c *Config) loadPackagesFromEnv() {
 		repo := os.Getenv(repoKey)
 
 		packageGroup := PackageGroup{
-			Owner: owner,
-			Repo:  repo,
+			Owner:     owner,
+			OwnerType: os.Getenv(ownerTypeKey),
+			Repo:      repo,
 		}
 
+		if intervalStr := os.Getenv(intervalKey); intervalStr != "" {
+			if interval, err := time.ParseDuration(intervalStr); err == nil {
+				packageGroup.Interval = Duration{Duration: interval}
+			}
+		}
+
+		if timeoutStr := os.Getenv(timeoutKey); timeoutStr != "" {
+			if timeout, err := time.ParseDuration(timeoutStr); err == nil {
+				packageGroup.Timeout = Duration{Duration: timeout}
+			}
+		}
+
+		if cadenceStr := os.Getenv(publishCadenceKey); cadenceStr != "" {
+			if cadence, err := time.ParseDuration(cadenceStr); err == nil {
+				packageGroup.PublishCadence = Duration{Duration: cadence}
+			}
+		}
+
+		packageGroup.Include = splitList(os.Getenv(includeKey))
+		packageGroup.Exclude = splitList(os.Getenv(excludeKey))
+		packageGroup.Visibility = splitList(os.Getenv(visibilityKey))
+		packageGroup.Tags = splitList(os.Getenv(tagsKey))
+		packageGroup.MutableTags = splitList(os.Getenv(mutableTagsKey))
+		packageGroup.Platforms = splitList(os.Getenv(platformsKey))
+		packageGroup.Channels = loadChannelRulesFromEnv(i)
+
 		c.Packages = append(c.Packages, packageGroup)
 
 		fmt.Printf("Loaded package from env: owner=%s, repo=%s\n", owner, repo)
@@ -163,6 +526,39 @@ func (c *Config) loadPackagesFromEnv() {
 	fmt.Printf("Total packages loaded: %d\n", len(c.Packages))
 }
 
+// loadChannelRulesFromEnv loads the channel rules for package i from
+// GHCR_EXPORTER_PACKAGES_N_CHANNELS_M_PATTERN and GHCR_EXPORTER_PACKAGES_N_CHANNELS_M_CHANNEL
+func loadChannelRulesFromEnv(i int) []ChannelRule {
+	var rules []ChannelRule
+
+	for j := 0; j < 10; j++ { // Support up to 10 rules per package
+		pattern := os.Getenv(fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_CHANNELS_%d_PATTERN", i, j))
+		if pattern == "" {
+			continue
+		}
+
+		rules = append(rules, ChannelRule{
+			Pattern: pattern,
+			Channel: os.Getenv(fmt.Sprintf("GHCR_EXPORTER_PACKAGES_%d_CHANNELS_%d_CHANNEL", i, j)),
+		})
+	}
+
+	return rules
+}
+
+// splitList splits a comma-separated environment variable into trimmed values
+func splitList(value string) []string {
+	var items []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
 // Validate performs comprehensive validation of the configuration
 func (c *Config) Validate() error {
 	// Validate server configuration
@@ -185,6 +581,21 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("github config: %w", err)
 	}
 
+	// Validate registry configuration
+	if err := c.validateRegistryConfig(); err != nil {
+		return fmt.Errorf("registry config: %w", err)
+	}
+
+	// Validate collector configuration
+	if err := c.validateCollectorConfig(); err != nil {
+		return fmt.Errorf("collector config: %w", err)
+	}
+
+	// Validate package configuration
+	if err := c.validatePackagesConfig(); err != nil {
+		return fmt.Errorf("packages config: %w", err)
+	}
+
 	return nil
 }
 
@@ -235,11 +646,175 @@ func (c *Config) validateGitHubConfig() error {
 		return fmt.Errorf("github token is required")
 	}
 
+	if c.GitHub.MaxPages < 1 {
+		return fmt.Errorf("max pages must be at least 1, got %d", c.GitHub.MaxPages)
+	}
+
+	return nil
+}
+
+func (c *Config) validateRegistryConfig() error {
+	registryURL, err := url.Parse(c.GetRegistryURL())
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", c.Registry.URL, err)
+	}
+
+	if registryURL.Scheme != "http" && registryURL.Scheme != "https" {
+		return fmt.Errorf("url must use http or https, got %q", c.Registry.URL)
+	}
+
+	if registryURL.Host == "" {
+		return fmt.Errorf("url must include a host, got %q", c.Registry.URL)
+	}
+
+	if c.Registry.StorageWalksPerCycle < 1 {
+		return fmt.Errorf("storage walks per cycle must be at least 1, got %d", c.Registry.StorageWalksPerCycle)
+	}
+
+	return nil
+}
+
+func (c *Config) validateCollectorConfig() error {
+	if c.Collector.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", c.Collector.Concurrency)
+	}
+
+	if c.Collector.OwnerConcurrency < 1 {
+		return fmt.Errorf("owner concurrency must be at least 1, got %d", c.Collector.OwnerConcurrency)
+	}
+
+	retry := c.Collector.Retry
+	if retry.Attempts < 1 {
+		return fmt.Errorf("retry attempts must be at least 1, got %d", retry.Attempts)
+	}
+
+	if retry.BaseDelay.Duration <= 0 {
+		return fmt.Errorf("retry base delay must be positive, got %s", retry.BaseDelay.Duration)
+	}
+
+	if retry.MaxDelay.Duration < retry.BaseDelay.Duration {
+		return fmt.Errorf("retry max delay %s must not be less than the base delay %s", retry.MaxDelay.Duration, retry.BaseDelay.Duration)
+	}
+
+	if c.Collector.StaleGracePeriod != nil && c.Collector.StaleGracePeriod.Duration < 0 {
+		return fmt.Errorf("stale grace period must not be negative, got %s", c.Collector.StaleGracePeriod.Duration)
+	}
+
+	return nil
+}
+
+func (c *Config) validatePackagesConfig() error {
+	ownerTypes := make(map[string]string)
+	names := make(map[string]bool)
+
+	for i, group := range c.Packages {
+		if group.Owner == "" {
+			return fmt.Errorf("package %d: owner is required", i)
+		}
+
+		// Groups are collected and labelled by name, so duplicates would
+		// overwrite each other's metrics
+		name := strings.ToLower(group.GetName())
+		if names[name] {
+			if group.Repo == "" {
+				return fmt.Errorf("package %s: only one owner-wide group is supported per owner", group.GetName())
+			}
+
+			return fmt.Errorf("package %s: configured more than once", group.GetName())
+		}
+
+		names[name] = true
+
+		if group.OwnerType != "" {
+			if group.OwnerType != OwnerTypeUser && group.OwnerType != OwnerTypeOrg {
+				return fmt.Errorf("package %s: owner type must be %q or %q, got %q", group.GetName(), OwnerTypeUser, OwnerTypeOrg, group.OwnerType)
+			}
+
+			owner := strings.ToLower(group.Owner)
+			if existing, ok := ownerTypes[owner]; ok && existing != group.OwnerType {
+				return fmt.Errorf("package %s: owner type %q conflicts with %q set for another package of the same owner", group.GetName(), group.OwnerType, existing)
+			}
+
+			ownerTypes[owner] = group.OwnerType
+		}
+
+		if group.Interval.Duration != 0 {
+			if group.Interval.Seconds() < 1 {
+				return fmt.Errorf("package %s: interval must be at least 1 second, got %s", group.GetName(), group.Interval.Duration)
+			}
+
+			if group.Interval.Seconds() > 86400 {
+				return fmt.Errorf("package %s: interval must be at most 86400 seconds (24 hours), got %s", group.GetName(), group.Interval.Duration)
+			}
+		}
+
+		if group.Timeout.Duration != 0 {
+			if group.Timeout.Seconds() < 1 {
+				return fmt.Errorf("package %s: timeout must be at least 1 second, got %s", group.GetName(), group.Timeout.Duration)
+			}
+
+			if group.Timeout.Seconds() > c.GetPackageInterval(group) {
+				return fmt.Errorf("package %s: timeout %s must not exceed the collection interval of %d seconds", group.GetName(), group.Timeout.Duration, c.GetPackageInterval(group))
+			}
+		}
+
+		if group.PublishCadence.Duration < 0 {
+			return fmt.Errorf("package %s: publish cadence must not be negative, got %s", group.GetName(), group.PublishCadence.Duration)
+		}
+
+		if err := validatePackageFilters(group); err != nil {
+			return fmt.Errorf("package %s: %w", group.GetName(), err)
+		}
+
+		for _, platform := range group.Platforms {
+			if !validPlatform(platform) {
+				return fmt.Errorf("package %s: invalid platform %q, must be os/architecture or os/architecture/variant", group.GetName(), platform)
+			}
+		}
+
+		for j, rule := range group.Channels {
+			if _, err := rule.Compile(); err != nil {
+				return fmt.Errorf("package %s: channel rule %d: invalid pattern %q: %w", group.GetName(), j, rule.Pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validatePackageFilters(group PackageGroup) error {
+	if group.Repo != "" && group.HasFilters() {
+		return fmt.Errorf("include, exclude and visibility filters can only be used without a repo")
+	}
+
+	for _, pattern := range append(append([]string{}, group.Include...), group.Exclude...) {
+		if _, err := matchPattern(pattern, ""); err != nil {
+			return fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, group.Tags...), group.MutableTags...) {
+		if _, err := matchPattern(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+		}
+	}
+
+	for _, visibility := range group.Visibility {
+		if !validVisibilities[strings.ToLower(visibility)] {
+			return fmt.Errorf("invalid visibility %q, must be one of public, private or internal", visibility)
+		}
+	}
+
 	return nil
 }
 
-// GetPackageInterval returns the interval for a package group
+// GetPackageInterval returns the interval for a package group, preferring the
+// group's own interval over the global default
 func (c *Config) GetPackageInterval(group PackageGroup) int {
+	if group.Interval.Duration > 0 {
+		return group.Interval.Seconds()
+	}
+
 	if c.Metrics.Collection.DefaultIntervalSet {
 		return c.Metrics.Collection.DefaultInterval.Seconds()
 	}
@@ -247,6 +822,97 @@ func (c *Config) GetPackageInterval(group PackageGroup) int {
 	return 60 // Default to 60 seconds
 }
 
+// GetPackageTimeout returns the deadline for a single collection cycle of a
+// package group. Without an explicit timeout a cycle may use its whole interval.
+func (c *Config) GetPackageTimeout(group PackageGroup) time.Duration {
+	if group.Timeout.Duration > 0 {
+		return group.Timeout.Duration
+	}
+
+	return time.Duration(c.GetPackageInterval(group)) * time.Second
+}
+
+// GetMaxPages returns the maximum number of pages to follow for paginated
+// GitHub API listings
+func (c *Config) GetMaxPages() int {
+	if c.GitHub.MaxPages > 0 {
+		return c.GitHub.MaxPages
+	}
+
+	return 10
+}
+
+// GetStorageWalksPerCycle returns how many versions of a package may be walked
+// for the storage metrics in one cycle, so a large package's first walk is
+// spread over several cycles rather than using up the collection deadline
+func (c *Config) GetStorageWalksPerCycle() int {
+	if c.Registry.StorageWalksPerCycle > 0 {
+		return c.Registry.StorageWalksPerCycle
+	}
+
+	return 50
+}
+
+// GetRegistryURL returns the registry base URL without a trailing slash
+func (c *Config) GetRegistryURL() string {
+	if c.Registry.URL != "" {
+		return strings.TrimSuffix(c.Registry.URL, "/")
+	}
+
+	return DefaultRegistryURL
+}
+
+// GetConcurrency returns the maximum number of packages collected at once
+func (c *Config) GetConcurrency() int {
+	if c.Collector.Concurrency > 0 {
+		return c.Collector.Concurrency
+	}
+
+	return 8
+}
+
+// GetOwnerConcurrency returns the maximum number of packages collected at once
+// for a single owner
+func (c *Config) GetOwnerConcurrency() int {
+	if c.Collector.OwnerConcurrency > 0 {
+		return c.Collector.OwnerConcurrency
+	}
+
+	return 4
+}
+
+// GetRetryConfig returns the retry settings with defaults filled in for
+// anything left unset
+func (c *Config) GetRetryConfig() RetryConfig {
+	retry := c.Collector.Retry
+
+	if retry.Attempts == 0 {
+		retry.Attempts = 3
+	}
+
+	if retry.BaseDelay.Duration == 0 {
+		retry.BaseDelay = Duration{Duration: 2 * time.Second}
+	}
+
+	// A base delay above the default max delay raises the max to match
+	if retry.MaxDelay.Duration == 0 {
+		retry.MaxDelay = Duration{Duration: max(30*time.Second, retry.BaseDelay.Duration)}
+	}
+
+	return retry
+}
+
+// GetStaleGracePeriod returns how long metrics are kept for a package that
+// is no longer found before they are removed. Zero removes them as soon as a
+// package is missing.
+func (c *Config) GetStaleGracePeriod() time.Duration {
+	if c.Collector.StaleGracePeriod != nil {
+		return c.Collector.StaleGracePeriod.Duration
+	}
+
+	return time.Hour
+}
+
 // GetDisplayConfig returns configuration data safe for display
 // Overrides BaseConfig to include GitHub configuration
 func (c *Config) GetDisplayConfig() map[string]interface{} {
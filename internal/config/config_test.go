@@ -0,0 +1,558 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	promexporter_config "github.com/d0ugal/promexporter/config"
+)
+
+// newValidConfig returns a config that passes validation so individual tests
+// only need to tweak the fields they care about
+func newValidConfig() *Config {
+	cfg := &Config{}
+	cfg.GitHub.Token = promexporter_config.NewSensitiveString("test-token")
+	setDefaults(cfg)
+
+	return cfg
+}
+
+func TestGetPackageIntervalOverride(t *testing.T) {
+	cfg := newValidConfig()
+	cfg.Metrics.Collection.DefaultInterval = Duration{Duration: 5 * time.Minute}
+	cfg.Metrics.Collection.DefaultIntervalSet = true
+
+	testCases := []struct {
+		description string
+		group       PackageGroup
+		expected    int
+	}{
+		{
+			description: "Falls back to default interval",
+			group:       PackageGroup{Owner: "d0ugal", Repo: "filesystem-exporter"},
+			expected:    300,
+		},
+		{
+			description: "Uses group interval",
+			group:       PackageGroup{Owner: "d0ugal", Repo: "filesystem-exporter", Interval: Duration{Duration: time.Hour}},
+			expected:    3600,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := cfg.GetPackageInterval(tc.group); got != tc.expected {
+				t.Errorf("Expected interval %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetPackageTimeout(t *testing.T) {
+	cfg := newValidConfig()
+
+	group := PackageGroup{Owner: "d0ugal", Interval: Duration{Duration: 10 * time.Minute}}
+	if got := cfg.GetPackageTimeout(group); got != 10*time.Minute {
+		t.Errorf("Expected timeout to default to the interval, got %s", got)
+	}
+
+	group.Timeout = Duration{Duration: 2 * time.Minute}
+	if got := cfg.GetPackageTimeout(group); got != 2*time.Minute {
+		t.Errorf("Expected explicit timeout of 2m, got %s", got)
+	}
+}
+
+func TestValidatePackagesConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		group       PackageGroup
+		expectError bool
+	}{
+		{
+			description: "No overrides",
+			group:       PackageGroup{Owner: "d0ugal"},
+		},
+		{
+			description: "Valid interval and timeout",
+			group:       PackageGroup{Owner: "d0ugal", Interval: Duration{Duration: time.Hour}, Timeout: Duration{Duration: time.Minute}},
+		},
+		{
+			description: "Missing owner",
+			group:       PackageGroup{Repo: "filesystem-exporter"},
+			expectError: true,
+		},
+		{
+			description: "Interval too short",
+			group:       PackageGroup{Owner: "d0ugal", Interval: Duration{Duration: 500 * time.Millisecond}},
+			expectError: true,
+		},
+		{
+			description: "Interval too long",
+			group:       PackageGroup{Owner: "d0ugal", Interval: Duration{Duration: 48 * time.Hour}},
+			expectError: true,
+		},
+		{
+			description: "Timeout longer than interval",
+			group:       PackageGroup{Owner: "d0ugal", Interval: Duration{Duration: time.Minute}, Timeout: Duration{Duration: time.Hour}},
+			expectError: true,
+		},
+		{
+			description: "Valid publish cadence",
+			group:       PackageGroup{Owner: "d0ugal", PublishCadence: Duration{Duration: 26 * time.Hour}},
+		},
+		{
+			description: "Valid platforms",
+			group:       PackageGroup{Owner: "d0ugal", Platforms: []string{"linux/amd64", "linux/arm/v7"}},
+		},
+		{
+			description: "Platform without architecture",
+			group:       PackageGroup{Owner: "d0ugal", Platforms: []string{"linux"}},
+			expectError: true,
+		},
+		{
+			description: "Negative publish cadence",
+			group:       PackageGroup{Owner: "d0ugal", PublishCadence: Duration{Duration: -time.Hour}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Packages = []PackageGroup{tc.group}
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigPackageInterval(t *testing.T) {
+	t.Setenv("GHCR_EXPORTER_GITHUB_TOKEN", "test-token")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	data := []byte(`
+packages:
+  - owner: "d0ugal"
+    repo: "filesystem-exporter"
+    interval: "15m"
+    timeout: "2m"
+  - owner: "d0ugal"
+`)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Expected config to load, got: %v", err)
+	}
+
+	if len(cfg.Packages) != 2 {
+		t.Fatalf("Expected 2 packages, got %d", len(cfg.Packages))
+	}
+
+	if got := cfg.GetPackageInterval(cfg.Packages[0]); got != 900 {
+		t.Errorf("Expected interval 900, got %d", got)
+	}
+
+	if got := cfg.GetPackageTimeout(cfg.Packages[0]); got != 2*time.Minute {
+		t.Errorf("Expected timeout 2m, got %s", got)
+	}
+
+	if cfg.Packages[1].Interval.Duration != 0 {
+		t.Errorf("Expected no interval override, got %s", cfg.Packages[1].Interval.Duration)
+	}
+}
+
+func TestLoadConfigStaleGracePeriod(t *testing.T) {
+	t.Setenv("GHCR_EXPORTER_GITHUB_TOKEN", "test-token")
+
+	testCases := []struct {
+		description string
+		setting     string
+		expected    time.Duration
+	}{
+		{description: "Unset", expected: time.Hour},
+		{description: "Zero removes immediately", setting: `stale_grace_period: "0s"`, expected: 0},
+		{description: "Explicit", setting: `stale_grace_period: "10m"`, expected: 10 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+
+			data := []byte("collector:\n  concurrency: 8\n  " + tc.setting + "\npackages:\n  - owner: \"d0ugal\"\n")
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("Expected config to load, got: %v", err)
+			}
+
+			if got := cfg.GetStaleGracePeriod(); got != tc.expected {
+				t.Errorf("Expected a stale grace period of %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPackageGroupFilterReason(t *testing.T) {
+	group := PackageGroup{
+		Owner:      "d0ugal",
+		Include:    []string{"*-exporter", "/^promexporter$/"},
+		Exclude:    []string{"ci-*", "/-test-exporter$/"},
+		Visibility: []string{"public"},
+	}
+
+	testCases := []struct {
+		name       string
+		visibility string
+		expected   string
+	}{
+		{name: "filesystem-exporter", visibility: "public", expected: ""},
+		{name: "promexporter", visibility: "public", expected: ""},
+		{name: "filesystem-exporter", visibility: "private", expected: FilterReasonVisibility},
+		{name: "home-assistant", visibility: "public", expected: FilterReasonInclude},
+		{name: "ci-exporter", visibility: "public", expected: FilterReasonExclude},
+		{name: "mqtt-test-exporter", visibility: "public", expected: FilterReasonExclude},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name+"/"+tc.visibility, func(t *testing.T) {
+			if got := group.FilterReason(tc.name, tc.visibility); got != tc.expected {
+				t.Errorf("Expected reason %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if got := (PackageGroup{Owner: "d0ugal"}).FilterReason("anything", "private"); got != "" {
+		t.Errorf("Expected no filtering without filters, got %q", got)
+	}
+}
+
+func TestPackageGroupTracksTag(t *testing.T) {
+	group := PackageGroup{Owner: "d0ugal", Tags: []string{"latest", "/^v[0-9]+\\.[0-9]+$/"}}
+
+	for tag, expected := range map[string]bool{
+		"latest":     true,
+		"v1.2":       true,
+		"v1.2.3-rc1": false,
+		"sha-abc123": false,
+	} {
+		if got := group.TracksTag(tag); got != expected {
+			t.Errorf("Expected TracksTag(%q) to be %t, got %t", tag, expected, got)
+		}
+	}
+
+	if !(PackageGroup{Owner: "d0ugal"}).TracksTag("sha-abc123") {
+		t.Error("Expected all tags to be tracked without tag patterns")
+	}
+}
+
+func TestPackageGroupIsMutableTag(t *testing.T) {
+	defaults := PackageGroup{Owner: "d0ugal"}
+	if !defaults.IsMutableTag("latest") || defaults.IsMutableTag("v1.2.3") {
+		t.Error("Expected only latest to be mutable by default")
+	}
+
+	group := PackageGroup{Owner: "d0ugal", MutableTags: []string{"main", "/^nightly(-.+)?$/"}}
+
+	for tag, expected := range map[string]bool{
+		"main":               true,
+		"nightly-2026-10-01": true,
+		"latest":             false,
+		"v1.2.3":             false,
+	} {
+		if got := group.IsMutableTag(tag); got != expected {
+			t.Errorf("Expected IsMutableTag(%q) to be %t, got %t", tag, expected, got)
+		}
+	}
+}
+
+func TestValidatePackageFilters(t *testing.T) {
+	testCases := []struct {
+		description string
+		group       PackageGroup
+		expectError bool
+	}{
+		{
+			description: "Valid filters",
+			group:       PackageGroup{Owner: "d0ugal", Include: []string{"*-exporter"}, Exclude: []string{"/^ci-/"}, Visibility: []string{"public", "Internal"}},
+		},
+		{
+			description: "Filters with repo",
+			group:       PackageGroup{Owner: "d0ugal", Repo: "filesystem-exporter", Include: []string{"*"}},
+			expectError: true,
+		},
+		{
+			description: "Invalid glob",
+			group:       PackageGroup{Owner: "d0ugal", Include: []string{"[exporter"}},
+			expectError: true,
+		},
+		{
+			description: "Invalid regex",
+			group:       PackageGroup{Owner: "d0ugal", Exclude: []string{"/(ci/"}},
+			expectError: true,
+		},
+		{
+			description: "Invalid visibility",
+			group:       PackageGroup{Owner: "d0ugal", Visibility: []string{"secret"}},
+			expectError: true,
+		},
+		{
+			description: "Tags with repo",
+			group:       PackageGroup{Owner: "d0ugal", Repo: "filesystem-exporter", Tags: []string{"latest", "/^v[0-9]+/"}},
+		},
+		{
+			description: "Invalid tag pattern",
+			group:       PackageGroup{Owner: "d0ugal", Tags: []string{"/(v/"}},
+			expectError: true,
+		},
+		{
+			description: "Invalid mutable tag pattern",
+			group:       PackageGroup{Owner: "d0ugal", MutableTags: []string{"[main"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Packages = []PackageGroup{tc.group}
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRetryConfig(t *testing.T) {
+	cfg := newValidConfig()
+
+	retry := cfg.GetRetryConfig()
+	if retry.Attempts != 3 || retry.BaseDelay.Duration != 2*time.Second || retry.MaxDelay.Duration != 30*time.Second {
+		t.Errorf("Unexpected retry defaults: %+v", retry)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected defaults to validate, got: %v", err)
+	}
+
+	cfg.Collector.Retry.MaxDelay = Duration{Duration: time.Second}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error when max delay is less than base delay")
+	}
+
+	cfg.Collector.Retry = RetryConfig{BaseDelay: Duration{Duration: time.Minute}}
+	setDefaults(cfg)
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a base delay above the default max delay to validate, got: %v", err)
+	}
+
+	if got := cfg.GetRetryConfig().MaxDelay.Duration; got != time.Minute {
+		t.Errorf("Expected the max delay to default to the base delay of 1m, got %s", got)
+	}
+
+	cfg.Collector.Retry = RetryConfig{Attempts: -1, BaseDelay: Duration{Duration: time.Second}, MaxDelay: Duration{Duration: time.Second}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for negative attempts")
+	}
+}
+
+func TestValidateOwnerType(t *testing.T) {
+	testCases := []struct {
+		description string
+		packages    []PackageGroup
+		expectError bool
+	}{
+		{
+			description: "User and org owners",
+			packages:    []PackageGroup{{Owner: "d0ugal", OwnerType: OwnerTypeUser}, {Owner: "home-assistant", OwnerType: OwnerTypeOrg}},
+		},
+		{
+			description: "Invalid owner type",
+			packages:    []PackageGroup{{Owner: "d0ugal", OwnerType: "organization"}},
+			expectError: true,
+		},
+		{
+			description: "Conflicting owner types",
+			packages:    []PackageGroup{{Owner: "d0ugal", OwnerType: OwnerTypeUser, Repo: "filesystem-exporter"}, {Owner: "D0ugal", OwnerType: OwnerTypeOrg}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Packages = tc.packages
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDuplicatePackageGroups(t *testing.T) {
+	testCases := []struct {
+		description string
+		packages    []PackageGroup
+		expectError bool
+	}{
+		{
+			description: "Owner-wide group and a repo of the same owner, which the owner-wide group skips",
+			packages:    []PackageGroup{{Owner: "d0ugal"}, {Owner: "d0ugal", Repo: "filesystem-exporter", Interval: Duration{Duration: time.Minute}}},
+		},
+		{
+			description: "Two owner-wide groups for the same owner",
+			packages:    []PackageGroup{{Owner: "d0ugal", Include: []string{"*-exporter"}}, {Owner: "D0ugal", Exclude: []string{"*-exporter"}}},
+			expectError: true,
+		},
+		{
+			description: "Same repo twice",
+			packages:    []PackageGroup{{Owner: "d0ugal", Repo: "filesystem-exporter"}, {Owner: "d0ugal", Repo: "filesystem-exporter"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Packages = tc.packages
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateChannelRules(t *testing.T) {
+	testCases := []struct {
+		description string
+		rules       []ChannelRule
+		expectError bool
+	}{
+		{
+			description: "Channel capture group",
+			rules:       []ChannelRule{{Pattern: `^(?P<channel>nightly|stable)-`}},
+		},
+		{
+			description: "Explicit channel",
+			rules:       []ChannelRule{{Pattern: `^pr-[0-9]+$`, Channel: "pr"}},
+		},
+		{
+			description: "Channel template",
+			rules:       []ChannelRule{{Pattern: `^stable-(?P<minor>[0-9]+\.[0-9]+)$`, Channel: "stable-${minor}"}},
+		},
+		{
+			description: "No channel",
+			rules:       []ChannelRule{{Pattern: `^pr-[0-9]+$`}},
+			expectError: true,
+		},
+		{
+			description: "Invalid regex",
+			rules:       []ChannelRule{{Pattern: `^(?P<channel>nightly`}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Packages = []PackageGroup{{Owner: "d0ugal", Channels: tc.rules}}
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryConfig(t *testing.T) {
+	testCases := []struct {
+		description string
+		url         string
+		walks       int
+		expectError bool
+	}{
+		{description: "Default", url: ""},
+		{description: "Local registry", url: "http://localhost:5000/"},
+		{description: "Missing scheme", url: "ghcr.io", expectError: true},
+		{description: "Unsupported scheme", url: "ftp://ghcr.io", expectError: true},
+		{description: "Storage walks per cycle", walks: 10},
+		{description: "Negative storage walks per cycle", walks: -1, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := newValidConfig()
+			cfg.Registry.URL = tc.url
+
+			if tc.walks != 0 {
+				cfg.Registry.StorageWalksPerCycle = tc.walks
+			}
+
+			err := cfg.Validate()
+			if tc.expectError && err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("Expected no validation error, got: %v", err)
+			}
+		})
+	}
+
+	cfg := &Config{Registry: RegistryConfig{URL: "http://localhost:5000/"}}
+	if got := cfg.GetRegistryURL(); got != "http://localhost:5000" {
+		t.Errorf("Expected the trailing slash to be trimmed, got %q", got)
+	}
+}
+
+func TestPackageGroupMissingPlatforms(t *testing.T) {
+	group := PackageGroup{Owner: "d0ugal", Platforms: []string{"linux/amd64", "linux/arm64", "linux/arm/v7"}}
+
+	missing := group.MissingPlatforms([]string{"linux/amd64", "linux/arm64/v8", "linux/arm/v6"})
+	if len(missing) != 1 || missing[0] != "linux/arm/v7" {
+		t.Errorf("Expected only linux/arm/v7 to be missing, got %v", missing)
+	}
+
+	if missing := (PackageGroup{Owner: "d0ugal"}).MissingPlatforms(nil); len(missing) != 0 {
+		t.Errorf("Expected nothing missing without required platforms, got %v", missing)
+	}
+}
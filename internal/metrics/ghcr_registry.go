@@ -14,6 +14,69 @@ type GHCRRegistry struct {
 	PackageDownloadsGauge     *prometheus.GaugeVec
 	PackageLastPublishedGauge *prometheus.GaugeVec
 	PackageDownloadStatsGauge *prometheus.GaugeVec
+	PackageInfoGauge          *prometheus.GaugeVec
+
+	// GHCR publish cadence metrics
+	PackagePublishOverdueSecondsGauge *prometheus.GaugeVec
+	PackagePublishOverdueGauge        *prometheus.GaugeVec
+
+	// GHCR version metrics
+	PackageTaggedVersionsGauge        *prometheus.GaugeVec
+	PackageUntaggedVersionsGauge      *prometheus.GaugeVec
+	PackageOldestUntaggedVersionGauge *prometheus.GaugeVec
+	PackageVersionsTruncatedGauge     *prometheus.GaugeVec
+	PackageVersionsPublishedCounter   *prometheus.CounterVec
+	PackageVersionsDeletedCounter     *prometheus.CounterVec
+
+	// GHCR tag metrics
+	PackageTagInfoGauge    *prometheus.GaugeVec
+	PackageTagUpdatedGauge *prometheus.GaugeVec
+	PackageTagMovesCounter *prometheus.CounterVec
+
+	// GHCR release metrics, from tags that are semantic versions
+	PackageLatestReleaseInfoGauge         *prometheus.GaugeVec
+	PackageLatestStableTimestampGauge     *prometheus.GaugeVec
+	PackageLatestPrereleaseTimestampGauge *prometheus.GaugeVec
+	PackageReleasesGauge                  *prometheus.GaugeVec
+
+	// GHCR release channel metrics
+	PackageChannelVersionsGauge      *prometheus.GaugeVec
+	PackageChannelLastPublishedGauge *prometheus.GaugeVec
+
+	// OCI image metrics, per tag and platform
+	ImageSizeGauge   *prometheus.GaugeVec
+	ImageLayersGauge *prometheus.GaugeVec
+
+	// OCI image platform coverage metrics
+	ImagePlatformInfoGauge     *prometheus.GaugeVec
+	ImageMissingPlatformsGauge *prometheus.GaugeVec
+
+	// OCI image build metadata metrics
+	ImageBuildInfoGauge        *prometheus.GaugeVec
+	ImageCreatedTimestampGauge *prometheus.GaugeVec
+	ImagePublishDelayGauge     *prometheus.GaugeVec
+
+	// OCI image signature and attestation metrics
+	ImageSignedGauge            *prometheus.GaugeVec
+	ImageSBOMPresentGauge       *prometheus.GaugeVec
+	ImageProvenancePresentGauge *prometheus.GaugeVec
+
+	// OCI package storage metrics
+	PackageStorageBytesGauge       *prometheus.GaugeVec
+	PackageStorageSharedBytesGauge *prometheus.GaugeVec
+
+	// Owner discovery metrics
+	OwnerPackagesDiscoveredGauge *prometheus.GaugeVec
+	OwnerPackagesFilteredGauge   *prometheus.GaugeVec
+
+	// GitHub API rate limit metrics
+	RateLimitLimitGauge     *prometheus.GaugeVec
+	RateLimitRemainingGauge *prometheus.GaugeVec
+	RateLimitResetGauge     *prometheus.GaugeVec
+
+	// GitHub API response cache metrics
+	APICacheHitsCounter   prometheus.Counter
+	APICacheMissesCounter prometheus.Counter
 
 	// Collection statistics
 	CollectionFailedCounter  *prometheus.CounterVec
@@ -64,6 +127,387 @@ func NewGHCRRegistry(baseRegistry *promexporter_metrics.Registry) *GHCRRegistry
 
 	baseRegistry.AddMetricInfo("ghcr_package_last_published_timestamp", "Timestamp of the last published version for a GHCR package", []string{"owner", "repo"})
 
+	ghcr.PackageInfoGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_info",
+			Help: "Information about a GHCR package, always 1",
+		},
+		[]string{"owner", "repo", "visibility", "package_type", "repository", "html_url"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_info", "Information about a GHCR package, always 1", []string{"owner", "repo", "visibility", "package_type", "repository", "html_url"})
+
+	// GHCR publish cadence metrics
+	ghcr.PackagePublishOverdueSecondsGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_publish_overdue_seconds",
+			Help: "Seconds a GHCR package is past its expected publish cadence, 0 when on time",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_publish_overdue_seconds", "Seconds a GHCR package is past its expected publish cadence, 0 when on time", []string{"owner", "repo"})
+
+	ghcr.PackagePublishOverdueGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_publish_overdue",
+			Help: "Whether a GHCR package is past its expected publish cadence (1) or not (0)",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_publish_overdue", "Whether a GHCR package is past its expected publish cadence (1) or not (0)", []string{"owner", "repo"})
+
+	// GHCR version metrics
+	ghcr.PackageTaggedVersionsGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_tagged_versions",
+			Help: "Number of versions of a GHCR package with at least one tag",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_tagged_versions", "Number of versions of a GHCR package with at least one tag", []string{"owner", "repo"})
+
+	ghcr.PackageUntaggedVersionsGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_untagged_versions",
+			Help: "Number of versions of a GHCR package without any tags",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_untagged_versions", "Number of versions of a GHCR package without any tags", []string{"owner", "repo"})
+
+	ghcr.PackageOldestUntaggedVersionGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_oldest_untagged_version_age_seconds",
+			Help: "Age in seconds of the oldest untagged version of a GHCR package",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_oldest_untagged_version_age_seconds", "Age in seconds of the oldest untagged version of a GHCR package", []string{"owner", "repo"})
+
+	ghcr.PackageVersionsTruncatedGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_versions_truncated",
+			Help: "Whether the version listing of a GHCR package was cut off at github.max_pages (1) or not (0)",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_versions_truncated", "Whether the version listing of a GHCR package was cut off at github.max_pages (1) or not (0)", []string{"owner", "repo"})
+
+	ghcr.PackageVersionsPublishedCounter = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ghcr_package_versions_published_total",
+			Help: "Total number of new versions of a GHCR package seen between collections",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_versions_published_total", "Total number of new versions of a GHCR package seen between collections", []string{"owner", "repo"})
+
+	ghcr.PackageVersionsDeletedCounter = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ghcr_package_versions_deleted_total",
+			Help: "Total number of versions of a GHCR package that disappeared between collections",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_versions_deleted_total", "Total number of versions of a GHCR package that disappeared between collections", []string{"owner", "repo"})
+
+	// GHCR tag metrics
+	ghcr.PackageTagInfoGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_tag_info",
+			Help: "Tags of a GHCR package and the version each points to, always 1",
+		},
+		[]string{"owner", "repo", "tag", "version_id"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_tag_info", "Tags of a GHCR package and the version each points to, always 1", []string{"owner", "repo", "tag", "version_id"})
+
+	ghcr.PackageTagUpdatedGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_tag_updated_timestamp",
+			Help: "Timestamp of the last update to the version a GHCR package tag points to",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_tag_updated_timestamp", "Timestamp of the last update to the version a GHCR package tag points to", []string{"owner", "repo", "tag"})
+
+	ghcr.PackageTagMovesCounter = factory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ghcr_package_tag_moves_total",
+			Help: "Total number of times a GHCR package tag not expected to be mutable moved to a different version",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_tag_moves_total", "Total number of times a GHCR package tag not expected to be mutable moved to a different version", []string{"owner", "repo", "tag"})
+
+	// GHCR release metrics, from tags that are semantic versions
+	ghcr.PackageLatestReleaseInfoGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_latest_release_info",
+			Help: "Highest stable semantic version tagged for a GHCR package, always 1",
+		},
+		[]string{"owner", "repo", "version"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_latest_release_info", "Highest stable semantic version tagged for a GHCR package, always 1", []string{"owner", "repo", "version"})
+
+	ghcr.PackageLatestStableTimestampGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_latest_stable_release_timestamp",
+			Help: "Timestamp when the highest stable semantic version of a GHCR package was published",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_latest_stable_release_timestamp", "Timestamp when the highest stable semantic version of a GHCR package was published", []string{"owner", "repo"})
+
+	ghcr.PackageLatestPrereleaseTimestampGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_latest_prerelease_timestamp",
+			Help: "Timestamp when the highest pre-release semantic version of a GHCR package was published",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_latest_prerelease_timestamp", "Timestamp when the highest pre-release semantic version of a GHCR package was published", []string{"owner", "repo"})
+
+	ghcr.PackageReleasesGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_releases",
+			Help: "Number of stable semantic version releases of a GHCR package per major version",
+		},
+		[]string{"owner", "repo", "major"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_releases", "Number of stable semantic version releases of a GHCR package per major version", []string{"owner", "repo", "major"})
+
+	// GHCR release channel metrics
+	ghcr.PackageChannelVersionsGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_channel_versions",
+			Help: "Number of versions of a GHCR package with a tag in a release channel",
+		},
+		[]string{"owner", "repo", "channel"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_channel_versions", "Number of versions of a GHCR package with a tag in a release channel", []string{"owner", "repo", "channel"})
+
+	ghcr.PackageChannelLastPublishedGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_channel_last_published_timestamp",
+			Help: "Timestamp of the newest version of a GHCR package with a tag in a release channel",
+		},
+		[]string{"owner", "repo", "channel"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_channel_last_published_timestamp", "Timestamp of the newest version of a GHCR package with a tag in a release channel", []string{"owner", "repo", "channel"})
+
+	// OCI image metrics, per tag and platform
+	ghcr.ImageSizeGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_size_bytes",
+			Help: "Compressed size of an image in bytes, the sum of its layers and config",
+		},
+		[]string{"owner", "repo", "tag", "platform"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_size_bytes", "Compressed size of an image in bytes, the sum of its layers and config", []string{"owner", "repo", "tag", "platform"})
+
+	ghcr.ImageLayersGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_layers",
+			Help: "Number of layers in an image",
+		},
+		[]string{"owner", "repo", "tag", "platform"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_layers", "Number of layers in an image", []string{"owner", "repo", "tag", "platform"})
+
+	// OCI image platform coverage metrics
+	ghcr.ImagePlatformInfoGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_platform_info",
+			Help: "Platforms an image was built for, always 1",
+		},
+		[]string{"owner", "repo", "tag", "platform"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_platform_info", "Platforms an image was built for, always 1", []string{"owner", "repo", "tag", "platform"})
+
+	ghcr.ImageMissingPlatformsGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_missing_platforms",
+			Help: "Number of required platforms an image was not built for",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_missing_platforms", "Number of required platforms an image was not built for", []string{"owner", "repo", "tag"})
+
+	// OCI image build metadata metrics
+	ghcr.ImageBuildInfoGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_build_info",
+			Help: "Build metadata from the OCI annotations and labels of an image, always 1",
+		},
+		[]string{"owner", "repo", "tag", "revision", "version", "source"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_build_info", "Build metadata from the OCI annotations and labels of an image, always 1", []string{"owner", "repo", "tag", "revision", "version", "source"})
+
+	ghcr.ImageCreatedTimestampGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_created_timestamp",
+			Help: "Timestamp of when an image was built, from its OCI annotations, labels or config",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_created_timestamp", "Timestamp of when an image was built, from its OCI annotations, labels or config", []string{"owner", "repo", "tag"})
+
+	ghcr.ImagePublishDelayGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_publish_delay_seconds",
+			Help: "Seconds between an image being built and its package version being published",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_publish_delay_seconds", "Seconds between an image being built and its package version being published", []string{"owner", "repo", "tag"})
+
+	// OCI image signature and attestation metrics
+	ghcr.ImageSignedGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_signed",
+			Help: "Whether an image has a cosign or notation signature (1) or not (0)",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_signed", "Whether an image has a cosign or notation signature (1) or not (0)", []string{"owner", "repo", "tag"})
+
+	ghcr.ImageSBOMPresentGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_sbom_present",
+			Help: "Whether an image has an SBOM attestation (1) or not (0)",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_sbom_present", "Whether an image has an SBOM attestation (1) or not (0)", []string{"owner", "repo", "tag"})
+
+	ghcr.ImageProvenancePresentGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_image_provenance_present",
+			Help: "Whether an image has a SLSA provenance attestation (1) or not (0)",
+		},
+		[]string{"owner", "repo", "tag"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_image_provenance_present", "Whether an image has a SLSA provenance attestation (1) or not (0)", []string{"owner", "repo", "tag"})
+
+	// OCI package storage metrics
+	ghcr.PackageStorageBytesGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_storage_bytes",
+			Help: "Storage used by all versions of a GHCR package in bytes, counting each blob once",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_storage_bytes", "Storage used by all versions of a GHCR package in bytes, counting each blob once", []string{"owner", "repo"})
+
+	ghcr.PackageStorageSharedBytesGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_package_storage_shared_bytes",
+			Help: "Storage used by blobs shared between image manifests of a GHCR package in bytes, counting each blob once",
+		},
+		[]string{"owner", "repo"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_package_storage_shared_bytes", "Storage used by blobs shared between image manifests of a GHCR package in bytes, counting each blob once", []string{"owner", "repo"})
+
+	// Owner discovery metrics
+	ghcr.OwnerPackagesDiscoveredGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_owner_packages_discovered",
+			Help: "Number of container packages found for an owner in the last discovery",
+		},
+		[]string{"owner"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_owner_packages_discovered", "Number of container packages found for an owner in the last discovery", []string{"owner"})
+
+	ghcr.OwnerPackagesFilteredGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_owner_packages_filtered",
+			Help: "Number of discovered packages skipped by include, exclude or visibility filters in the last discovery",
+		},
+		[]string{"owner", "reason"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_owner_packages_filtered", "Number of discovered packages skipped by include, exclude or visibility filters in the last discovery", []string{"owner", "reason"})
+
+	// GitHub API rate limit metrics
+	ghcr.RateLimitLimitGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_github_rate_limit_limit",
+			Help: "Maximum number of GitHub API requests allowed in the current rate limit window",
+		},
+		[]string{"resource"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_github_rate_limit_limit", "Maximum number of GitHub API requests allowed in the current rate limit window", []string{"resource"})
+
+	ghcr.RateLimitRemainingGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_github_rate_limit_remaining",
+			Help: "Number of GitHub API requests remaining in the current rate limit window",
+		},
+		[]string{"resource"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_github_rate_limit_remaining", "Number of GitHub API requests remaining in the current rate limit window", []string{"resource"})
+
+	ghcr.RateLimitResetGauge = factory.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ghcr_github_rate_limit_reset_timestamp",
+			Help: "Unix timestamp when the current GitHub API rate limit window resets",
+		},
+		[]string{"resource"},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_github_rate_limit_reset_timestamp", "Unix timestamp when the current GitHub API rate limit window resets", []string{"resource"})
+
+	// GitHub API response cache metrics
+	ghcr.APICacheHitsCounter = factory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ghcr_github_api_cache_hits_total",
+			Help: "Total number of GitHub API requests answered 304 Not Modified and served from the cache",
+		},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_github_api_cache_hits_total", "Total number of GitHub API requests answered 304 Not Modified and served from the cache", []string{})
+
+	ghcr.APICacheMissesCounter = factory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ghcr_github_api_cache_misses_total",
+			Help: "Total number of GitHub API requests that returned a full response body",
+		},
+	)
+
+	baseRegistry.AddMetricInfo("ghcr_github_api_cache_misses_total", "Total number of GitHub API requests that returned a full response body", []string{})
+
 	// Collection statistics
 	ghcr.CollectionFailedCounter = factory.NewCounterVec(
 		prometheus.CounterOpts{